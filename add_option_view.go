@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/76creates/stickers/flexbox"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -28,6 +30,9 @@ var (
 					Foreground(lipgloss.Color("170")). // Fuchsia/magenta color used for list selection
 					Bold(true)
 
+	addOptionWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	addOptionHintStyle    = lipgloss.NewStyle().Faint(true)
+
 	// Style for centering
 	addOptionStyleCentered = lipgloss.NewStyle().Align(lipgloss.Center)
 	addOptionStyleContent  = lipgloss.NewStyle().Align(lipgloss.Center).Padding(3, 6)
@@ -39,9 +44,17 @@ type AddOptionView struct {
 	CommentInput    textinput.Model
 	InputFocus      int              // 0=flag, 1=comment, 2=add button, 3=cancel button
 	LastButtonFocus int              // Remembers last focused button (2 or 3)
+	Error           string           // Why the flag can't be added
+	Warning         string           // Why the flag might be wrong, Add again to add it anyway
+	Catalog         *OptionCatalog   // Options of the installed yt-dlp, nil until loaded
 	FlexBox         *flexbox.FlexBox // For centering content
+
+	autoComment string // Comment filled in from the catalog, replaced when the flag changes
 }
 
+// maxCompletions limits how many matching options are listed below the flag
+const maxCompletions = 5
+
 // NewAddOptionView creates a new AddOptionView instance
 func NewAddOptionView() AddOptionView {
 	// Create input fields
@@ -50,6 +63,9 @@ func NewAddOptionView() AddOptionView {
 	flagInput.CharLimit = 256
 	flagInput.Width = 120
 	flagInput.Focus() // Focus on the first input by default
+	// Up and down move between fields, Tab accepts a suggestion
+	flagInput.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	flagInput.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
 
 	commentInput := textinput.New()
 	commentInput.Placeholder = "(optional)"
@@ -120,6 +136,16 @@ func (av *AddOptionView) Update(msg tea.Msg) tea.Cmd {
 				flag := av.FlagInput.Value()
 				comment := av.CommentInput.Value()
 				if flag != "" {
+					// Quotes have to be balanced for the flag to become arguments
+					if err := validateFlag(flag); err != nil {
+						av.Error = "Invalid flag: " + err.Error()
+						return nil
+					}
+					// Typos would only show up as yt-dlp errors when downloading
+					if warning := av.Catalog.CheckFlag(flag); warning != "" && av.Warning == "" {
+						av.Warning = warning
+						return nil
+					}
 					return tea.Cmd(func() tea.Msg {
 						return AddOptionMsg{Flag: flag, Comment: comment}
 					})
@@ -132,7 +158,13 @@ func (av *AddOptionView) Update(msg tea.Msg) tea.Cmd {
 		default:
 			// Pass keys to the focused input
 			if av.InputFocus == 0 {
+				previous := av.FlagInput.Value()
 				av.FlagInput, cmd = av.FlagInput.Update(msg)
+				if av.FlagInput.Value() != previous {
+					av.Error = ""
+					av.Warning = ""
+					av.fillComment()
+				}
 			} else if av.InputFocus == 1 {
 				av.CommentInput, cmd = av.CommentInput.Update(msg)
 			}
@@ -142,6 +174,36 @@ func (av *AddOptionView) Update(msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
+// SetCatalog enables completion and checks of flags against the options of yt-dlp
+func (av *AddOptionView) SetCatalog(catalog *OptionCatalog) {
+	av.Catalog = catalog
+	var names []string
+	for _, option := range catalog.Options {
+		names = append(names, option.Name)
+	}
+	av.FlagInput.SetSuggestions(names)
+	av.FlagInput.ShowSuggestions = true
+}
+
+// flagOption returns the catalog entry of the flag being typed
+func (av AddOptionView) flagOption() (CatalogOption, bool) {
+	name, _, _ := strings.Cut(strings.TrimSpace(av.FlagInput.Value()), " ")
+	name, _, _ = strings.Cut(name, "=")
+	return av.Catalog.Lookup(name)
+}
+
+// fillComment describes the option in the comment unless the user wrote their own
+func (av *AddOptionView) fillComment() {
+	if av.CommentInput.Value() != av.autoComment {
+		return
+	}
+	av.autoComment = ""
+	if option, ok := av.flagOption(); ok {
+		av.autoComment = option.Summary()
+	}
+	av.CommentInput.SetValue(av.autoComment)
+}
+
 // updateInputFocus sets focus on the correct input field
 func (av *AddOptionView) updateInputFocus() {
 	switch av.InputFocus {
@@ -161,6 +223,9 @@ func (av *AddOptionView) updateInputFocus() {
 func (av *AddOptionView) Reset() {
 	av.FlagInput.Reset()
 	av.CommentInput.Reset()
+	av.Error = ""
+	av.Warning = ""
+	av.autoComment = ""
 	av.InputFocus = 0
 	av.updateInputFocus()
 	// Don't reset LastButtonFocus - keep memory of last button
@@ -212,7 +277,15 @@ func (av AddOptionView) buildContent() string {
 	if av.InputFocus == 0 {
 		flagLabel = addOptionFocusedLabelStyle.Render("Flag:")
 	}
-	s += flagLabel + "\n" + av.FlagInput.View() + "\n\n"
+	s += flagLabel + "\n" + av.FlagInput.View() + "\n"
+	if av.Error != "" {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ "+av.Error) + "\n"
+	} else if av.Warning != "" {
+		s += addOptionWarningStyle.Render("⚠ "+av.Warning+" • Add again to keep it") + "\n"
+	} else if av.InputFocus == 0 {
+		s += av.buildHints()
+	}
+	s += "\n"
 
 	commentLabel := "Comment:"
 	if av.InputFocus == 1 {
@@ -243,3 +316,30 @@ func (av AddOptionView) buildContent() string {
 
 	return s
 }
+
+// buildHints describes the option being typed, or lists the options it could be completed to
+func (av AddOptionView) buildHints() string {
+	hint := addOptionHintStyle.MaxWidth(av.FlagInput.Width)
+	if option, ok := av.flagOption(); ok {
+		return hint.Render(option.Usage()+"  "+option.Summary()) + "\n"
+	}
+
+	value := av.FlagInput.Value()
+	if len(value) <= len("--") || !strings.HasPrefix(value, "--") || strings.ContainsAny(value, " =") {
+		return ""
+	}
+	matches := av.Catalog.Complete(value)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	var names []string
+	for i, option := range matches {
+		if i == maxCompletions {
+			names = append(names, fmt.Sprintf("… %d more", len(matches)-maxCompletions))
+			break
+		}
+		names = append(names, option.Name)
+	}
+	return hint.Render(strings.Join(names, " • ")) + "\n"
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveRecord describes when and where an archived video was downloaded
+type ArchiveRecord struct {
+	URL   string    `json:"url"`
+	Title string    `json:"title"`
+	Path  string    `json:"path"`
+	Date  time.Time `json:"date"`
+}
+
+// Archive is a download archive in yt-dlp's --download-archive format,
+// with a JSON sidecar remembering the date and path of every download
+type Archive struct {
+	Path     string
+	Records  map[string]ArchiveRecord // Keyed by "extractor id", like the archive lines
+	loadedAt archiveStamp             // Modification times of the files when they were read
+}
+
+// archiveStamp holds the modification times of an archive file and its sidecar
+type archiveStamp struct {
+	archive time.Time
+	sidecar time.Time
+}
+
+// archives caches every archive opened so far by path
+var archives = map[string]*Archive{}
+
+// getArchiveFilePath returns the path of the global download archive
+func getArchiveFilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "archive.txt"), nil
+}
+
+// archiveKey returns the archive line yt-dlp uses for a video
+func archiveKey(extractorKey, id string) string {
+	if extractorKey == "" || id == "" {
+		return ""
+	}
+	return strings.ToLower(extractorKey) + " " + id
+}
+
+// OpenArchive loads the archive at path, or returns an empty one if it doesn't exist yet.
+// A cached archive is read again if yt-dlp or another babago changed it in the meantime.
+func OpenArchive(path string) (*Archive, error) {
+	path = expandHome(path)
+	if archive, ok := archives[path]; ok && archive.stamp().equal(archive.loadedAt) {
+		return archive, nil
+	}
+
+	archive := &Archive{Path: path, Records: make(map[string]ArchiveRecord)}
+	archive.loadedAt = archive.stamp()
+
+	// Archive lines written by yt-dlp itself have no details
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if key := strings.TrimSpace(scanner.Text()); key != "" {
+				archive.Records[key] = ArchiveRecord{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fill in details of the downloads babago made
+	data, err := os.ReadFile(archive.sidecarPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		var records map[string]ArchiveRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+		for key, record := range records {
+			if _, ok := archive.Records[key]; ok {
+				archive.Records[key] = record
+			}
+		}
+	}
+
+	archives[path] = archive
+	return archive, nil
+}
+
+// sidecarPath returns the path of the JSON file holding download details
+func (a *Archive) sidecarPath() string {
+	return a.Path + ".json"
+}
+
+// stamp returns the current modification times of the archive files, zero for missing ones
+func (a *Archive) stamp() archiveStamp {
+	var stamp archiveStamp
+	if info, err := os.Stat(a.Path); err == nil {
+		stamp.archive = info.ModTime()
+	}
+	if info, err := os.Stat(a.sidecarPath()); err == nil {
+		stamp.sidecar = info.ModTime()
+	}
+	return stamp
+}
+
+// equal reports whether both modification times match
+func (s archiveStamp) equal(other archiveStamp) bool {
+	return s.archive.Equal(other.archive) && s.sidecar.Equal(other.sidecar)
+}
+
+// Lookup returns the record of an archived video
+func (a *Archive) Lookup(key string) (ArchiveRecord, bool) {
+	record, ok := a.Records[key]
+	return record, ok
+}
+
+// Add records a downloaded video, appending it to the archive file
+func (a *Archive) Add(key string, record ArchiveRecord) error {
+	if key == "" {
+		return nil
+	}
+
+	if _, ok := a.Records[key]; !ok {
+		if err := os.MkdirAll(filepath.Dir(a.Path), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(a.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(key + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	a.Records[key] = record
+
+	// Only keep details of downloads that are still archived
+	details := make(map[string]ArchiveRecord)
+	for key, record := range a.Records {
+		if !record.Date.IsZero() {
+			details[key] = record
+		}
+	}
+	data, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(a.sidecarPath(), data, 0644); err != nil {
+		return err
+	}
+
+	// Our own changes are already in Records
+	a.loadedAt = a.stamp()
+	return nil
+}
+
+// RecordFiles adds every file of a finished download, keys and files in the order yt-dlp reported them
+func (a *Archive) RecordFiles(url, title string, keys, files []string) error {
+	for i, key := range keys {
+		record := ArchiveRecord{URL: url, Title: title, Date: time.Now()}
+		if i < len(files) {
+			record.Path = files[i]
+		}
+		if err := a.Add(key, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordReport adds the downloads listed in a yt-dlp --print-to-file report to the archive at path
+func recordReport(path, url, reportPath string) error {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return err
+	}
+
+	var progress DownloadProgress
+	for _, line := range strings.Split(string(data), "\n") {
+		parseProgressLine(&progress, line)
+	}
+	if len(progress.ArchiveKeys) == 0 {
+		return nil
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		return err
+	}
+	title := generateVideoName(url)
+	if len(progress.Files) > 0 {
+		title = filepath.Base(progress.Files[0])
+	}
+	return archive.RecordFiles(url, title, progress.ArchiveKeys, progress.Files)
+}
+
+// Describe returns a short description of when and where a video was downloaded
+func (r ArchiveRecord) Describe() string {
+	if r.Date.IsZero() {
+		return "Already in download archive"
+	}
+	description := "Already downloaded on " + r.Date.Format("2006-01-02 15:04")
+	if r.Path != "" {
+		description += " to " + r.Path
+	}
+	return description
+}
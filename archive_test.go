@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveKey(t *testing.T) {
+	tests := []struct {
+		extractor, id, want string
+	}{
+		{"Youtube", "dQw4w9WgXcQ", "youtube dQw4w9WgXcQ"},
+		{"BiliBili", "BV1xx", "bilibili BV1xx"},
+		{"", "abc", ""},
+		{"Youtube", "", ""},
+	}
+
+	for _, test := range tests {
+		if got := archiveKey(test.extractor, test.id); got != test.want {
+			t.Errorf("archiveKey(%q, %q) = %q, want %q", test.extractor, test.id, got, test.want)
+		}
+	}
+}
+
+func TestArchiveRecordAndLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.txt")
+
+	// Lines written by yt-dlp's own --download-archive have no details
+	if err := os.WriteFile(path, []byte("youtube old\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local)
+	if err := archive.Add("youtube abc", ArchiveRecord{URL: "https://youtu.be/abc", Title: "Clip", Path: "/videos/clip.mp4", Date: date}); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Add("", ArchiveRecord{Date: date}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read everything back from disk
+	delete(archives, path)
+	archive, err = OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.Records) != 2 {
+		t.Errorf("archive has %d records, want 2", len(archive.Records))
+	}
+
+	tests := []struct {
+		key      string
+		wantOK   bool
+		wantDesc string
+	}{
+		{"youtube abc", true, "Already downloaded on 2024-05-01 12:30 to /videos/clip.mp4"},
+		{"youtube old", true, "Already in download archive"},
+		{"youtube new", false, ""},
+	}
+	for _, test := range tests {
+		record, ok := archive.Lookup(test.key)
+		if ok != test.wantOK {
+			t.Errorf("Lookup(%q) found = %v, want %v", test.key, ok, test.wantOK)
+			continue
+		}
+		if ok && record.Describe() != test.wantDesc {
+			t.Errorf("Lookup(%q).Describe() = %q, want %q", test.key, record.Describe(), test.wantDesc)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "youtube old\n\nyoutube abc\n" {
+		t.Errorf("archive file = %q, want yt-dlp's format", data)
+	}
+}
+
+func TestOpenArchiveReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.txt")
+	if err := os.WriteFile(path, []byte("youtube abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := OpenArchive(path); again != archive {
+		t.Error("unchanged archive was read again")
+	}
+
+	// yt-dlp appends to the archive on its own when it's passed --download-archive
+	if err := os.WriteFile(path, []byte("youtube abc\nyoutube def\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err = OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := archive.Lookup("youtube def"); !ok {
+		t.Error("archive wasn't reloaded after the file changed")
+	}
+}
+
+func TestRecordReport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.txt")
+	reportPath := filepath.Join(dir, "report.txt")
+	report := "[babago-file]/videos/a.mp4\n[babago-archive]Youtube a\n[babago-file]/videos/b.mp4\n[babago-archive]Youtube b\n"
+	if err := os.WriteFile(reportPath, []byte(report), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recordReport(path, "https://youtube.com/playlist?list=x", reportPath); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"youtube a": "/videos/a.mp4", "youtube b": "/videos/b.mp4"} {
+		record, ok := archive.Lookup(key)
+		if !ok || record.Path != want {
+			t.Errorf("Lookup(%q) = %+v, %v, want path %q", key, record, ok, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runBatch downloads every URL listed in a file, or stdin when the file is "-"
+func runBatch(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: No batch file given")
+		fmt.Println("Usage: babago batch FILE|- [yt-dlp options...]")
+		os.Exit(1)
+	}
+
+	urls, rejected, err := loadURLList(args[0])
+	if err != nil {
+		fmt.Printf("Error reading batch file: %v\n", err)
+		os.Exit(1)
+	}
+	if len(rejected) > 0 {
+		fmt.Printf("Warning: Skipping invalid URLs on %s\n", lineList(rejected))
+	}
+	if len(urls) == 0 {
+		fmt.Println("Error: No valid URL found in batch file")
+		os.Exit(1)
+	}
+
+	logToFile(fmt.Sprintf("Running batch of %d URLs from %s", len(urls), args[0]))
+
+	catalog := cliCatalog()
+	options, extra := parseCLIArgs(args[1:], catalog)
+	for _, arg := range extra {
+		fmt.Printf("Warning: ignoring %q, it's not the value of an option\n", arg)
+	}
+
+	if failed := runURLsDirect(urls, options, catalog); failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadURLList reads URLs from path, or from stdin when path is "-"
+func loadURLList(path string) ([]string, []int, error) {
+	if path == "-" {
+		return readURLList(os.Stdin)
+	}
+
+	f, err := os.Open(expandHome(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	return readURLList(f)
+}
+
+// readURLList reads one URL per line, skipping blank lines and comments.
+// Comments start with '#', ';' or ']' like in yt-dlp's --batch-file.
+// It also returns the numbers of the lines that aren't valid URLs.
+func readURLList(r io.Reader) ([]string, []int, error) {
+	var urls []string
+	var rejected []int
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.ContainsAny(line[:1], "#;]") {
+			continue
+		}
+
+		if !isValidURL(line) {
+			logToFile(fmt.Sprintf("Skipping invalid URL on line %d of batch file: %s", number, line))
+			rejected = append(rejected, number)
+			continue
+		}
+		urls = append(urls, line)
+	}
+
+	return urls, rejected, scanner.Err()
+}
+
+// lineList describes line numbers for a warning, e.g. "lines 3, 7 and 9"
+func lineList(lines []int) string {
+	const maxShown = 5
+
+	var parts []string
+	for i, line := range lines {
+		if i == maxShown {
+			parts = append(parts, fmt.Sprintf("%d more", len(lines)-maxShown))
+			break
+		}
+		parts = append(parts, strconv.Itoa(line))
+	}
+
+	if len(parts) == 1 {
+		return "line " + parts[0]
+	}
+	return "lines " + strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
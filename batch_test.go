@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadURLList(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantURLs     []string
+		wantRejected []int
+	}{
+		{
+			name:     "one per line",
+			input:    "https://example.com/1\nhttps://example.com/2\n",
+			wantURLs: []string{"https://example.com/1", "https://example.com/2"},
+		},
+		{
+			name:     "comments and blank lines",
+			input:    "# videos\n\nhttps://example.com/1\n; old\n] skipped\n  https://example.com/2  \n",
+			wantURLs: []string{"https://example.com/1", "https://example.com/2"},
+		},
+		{
+			name:         "invalid lines",
+			input:        "https://example.com/1\nnot a url\n# comment\nexample.com/2\nhttps://example.com/3",
+			wantURLs:     []string{"https://example.com/1", "https://example.com/3"},
+			wantRejected: []int{2, 4},
+		},
+		{
+			name:  "empty",
+			input: "",
+		},
+	}
+
+	for _, test := range tests {
+		urls, rejected, err := readURLList(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(urls, test.wantURLs) {
+			t.Errorf("%s: urls = %q, want %q", test.name, urls, test.wantURLs)
+		}
+		if !reflect.DeepEqual(rejected, test.wantRejected) {
+			t.Errorf("%s: rejected = %v, want %v", test.name, rejected, test.wantRejected)
+		}
+	}
+}
+
+func TestLineList(t *testing.T) {
+	tests := []struct {
+		lines []int
+		want  string
+	}{
+		{[]int{3}, "line 3"},
+		{[]int{3, 7}, "lines 3 and 7"},
+		{[]int{3, 7, 9}, "lines 3, 7 and 9"},
+		{[]int{1, 2, 3, 4, 5, 6, 7}, "lines 1, 2, 3, 4, 5 and 2 more"},
+	}
+
+	for _, test := range tests {
+		if got := lineList(test.lines); got != test.want {
+			t.Errorf("lineList(%v) = %q, want %q", test.lines, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseCLIArgs splits command line arguments into yt-dlp options and the remaining
+// arguments, like URLs. Flags take as many values as the catalog says, even values
+// starting with "-", and bundled short switches like -xk become separate options.
+// Without the catalog a flag takes the next argument unless it looks like a flag or URL.
+func parseCLIArgs(args []string, catalog *OptionCatalog) ([]Option, []string) {
+	var options []Option
+	var positional []string
+
+	addOption := func(args ...string) {
+		options = append(options, Option{
+			Flag:    shellJoin(args),
+			Args:    args,
+			Comment: "From CLI arguments",
+			Enabled: true,
+			Source:  sourceCommandLine,
+		})
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			// Everything after -- is a URL
+			positional = append(positional, args[i+1:]...)
+			return options, positional
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(name, "--") {
+			// Short flags: switches can be bundled, a flag taking a value ends the bundle
+			if bundle, ok := splitShortFlags(arg, catalog); ok {
+				for _, flag := range bundle[:len(bundle)-1] {
+					addOption(flag)
+				}
+				// The last flag may have its value attached, e.g. -fbest
+				arg = bundle[len(bundle)-1]
+				name, hasValue = arg[:2], len(arg) > 2
+			}
+		}
+
+		option, known := catalog.Lookup(name)
+		var arity int
+		switch {
+		case known:
+			arity = option.Arity()
+			if hasValue {
+				// A switch given a value, like --extract-audio=yes, is passed on for yt-dlp to reject
+				arity = max(arity-1, 0)
+			}
+		case catalog != nil:
+			logToFile("Unknown yt-dlp option on the command line: " + name)
+			fallthrough
+		default:
+			// Guess: a value follows unless the next argument is a flag or a URL
+			if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && !isValidURL(args[i+1]) {
+				arity = 1
+			}
+		}
+
+		// Values are taken as they are, even if they start with "-"
+		end := min(i+1+arity, len(args))
+		addOption(append([]string{arg}, args[i+1:end]...)...)
+		i = end - 1
+	}
+
+	return options, positional
+}
+
+// splitShortFlags splits bundled short flags like "-xk" into "-x" and "-k". If a flag takes
+// a value, the rest of the bundle is its value, e.g. "-xfbest" is "-x" and "-fbest".
+// It returns false if the bundle has unknown flags or isn't a bundle.
+func splitShortFlags(arg string, catalog *OptionCatalog) ([]string, bool) {
+	if catalog == nil || len(arg) <= 2 || strings.HasPrefix(arg, "--") {
+		return nil, false
+	}
+
+	var flags []string
+	for i := 1; i < len(arg); i++ {
+		flag := "-" + arg[i:i+1]
+		option, ok := catalog.Lookup(flag)
+		if !ok {
+			return nil, false
+		}
+		if option.Arity() > 0 {
+			// The rest of the bundle is the value
+			return append(flags, flag+arg[i+1:]), true
+		}
+		flags = append(flags, flag)
+	}
+	return flags, true
+}
+
+// cliCatalog checks yt-dlp and returns its options for parsing command line arguments,
+// or nil if they can't be read
+func cliCatalog() *OptionCatalog {
+	// Warn early about a missing or outdated yt-dlp
+	version, err := checkYtDlp()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if version == "" {
+		return nil
+	}
+
+	catalog, err := LoadOptionCatalog(version)
+	if err != nil {
+		logToFile("Failed to load yt-dlp options: " + err.Error())
+		return nil
+	}
+	return catalog
+}
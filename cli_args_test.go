@@ -0,0 +1,169 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCLIArgs(t *testing.T) {
+	const url = "https://youtu.be/abc"
+
+	tests := []struct {
+		name           string
+		args           []string
+		withCatalog    bool
+		wantOptions    [][]string
+		wantPositional []string
+	}{
+		{
+			"values starting with a dash",
+			[]string{url, "-x", "--audio-format", "mp3", "-f", "-bestaudio"}, true,
+			[][]string{{"-x"}, {"--audio-format", "mp3"}, {"-f", "-bestaudio"}},
+			[]string{url},
+		},
+		{
+			"URL after the options",
+			[]string{"-x", "--audio-format", "mp3", "-f", "-bestaudio", url}, true,
+			[][]string{{"-x"}, {"--audio-format", "mp3"}, {"-f", "-bestaudio"}},
+			[]string{url},
+		},
+		{
+			"switch followed by a URL",
+			[]string{"-x", url}, true,
+			[][]string{{"-x"}},
+			[]string{url},
+		},
+		{
+			"bundled switches",
+			[]string{"-xk", url}, true,
+			[][]string{{"-x"}, {"-k"}},
+			[]string{url},
+		},
+		{
+			"attached short value",
+			[]string{"-fbest", url}, true,
+			[][]string{{"-fbest"}},
+			[]string{url},
+		},
+		{
+			"bundle ending in a flag with a value",
+			[]string{"-xf", "best", "-kfbest"}, true,
+			[][]string{{"-x"}, {"-f", "best"}, {"-k"}, {"-fbest"}},
+			nil,
+		},
+		{
+			"value after =",
+			[]string{"--format=best", url}, true,
+			[][]string{{"--format=best"}},
+			[]string{url},
+		},
+		{
+			"switch given a value",
+			[]string{"--extract-audio=yes", url}, true,
+			[][]string{{"--extract-audio=yes"}},
+			[]string{url},
+		},
+		{
+			"short switch given a value",
+			[]string{"-x=1", url}, true,
+			[][]string{{"-x=1"}},
+			[]string{url},
+		},
+		{
+			"flag with two values",
+			[]string{"--print-to-file", "title", "titles.txt", url}, true,
+			[][]string{{"--print-to-file", "title", "titles.txt"}},
+			[]string{url},
+		},
+		{
+			"missing value",
+			[]string{"--format"}, true,
+			[][]string{{"--format"}},
+			nil,
+		},
+		{
+			"unknown flag followed by a URL",
+			[]string{"--bogus", url}, true,
+			[][]string{{"--bogus"}},
+			[]string{url},
+		},
+		{
+			"end of options",
+			[]string{"-x", "--", "-not-a-flag", url}, true,
+			[][]string{{"-x"}},
+			[]string{"-not-a-flag", url},
+		},
+		{
+			"stray argument",
+			[]string{"-x", "stray"}, true,
+			[][]string{{"-x"}},
+			[]string{"stray"},
+		},
+		{
+			"guessing without a catalog",
+			[]string{"--format", "best", "-x", url}, false,
+			[][]string{{"--format", "best"}, {"-x"}},
+			[]string{url},
+		},
+		{
+			"values starting with a dash aren't guessed",
+			[]string{"-f", "-bestaudio"}, false,
+			[][]string{{"-f"}, {"-bestaudio"}},
+			nil,
+		},
+	}
+
+	catalog := testCatalog(t)
+	for _, test := range tests {
+		var c *OptionCatalog
+		if test.withCatalog {
+			c = catalog
+		}
+		options, positional := parseCLIArgs(test.args, c)
+
+		var got [][]string
+		for _, option := range options {
+			got = append(got, option.Arguments())
+			if option.Source != sourceCommandLine {
+				t.Errorf("%s: option %q has source %q", test.name, option.Flag, option.Source)
+			}
+		}
+		if !reflect.DeepEqual(got, test.wantOptions) {
+			t.Errorf("%s: options = %q, want %q", test.name, got, test.wantOptions)
+		}
+		if !reflect.DeepEqual(positional, test.wantPositional) {
+			t.Errorf("%s: positional = %q, want %q", test.name, positional, test.wantPositional)
+		}
+	}
+}
+
+func TestGetMergedOptionsWithCLIArgs(t *testing.T) {
+	pv := PresetsView{
+		Presets: []Preset{{
+			Name:   "Defaults",
+			Active: true,
+			Options: []Option{
+				{Flag: "--format=best", Enabled: true},
+				{Flag: "--print title", Enabled: true},
+			},
+		}},
+		Catalog: testCatalog(t),
+	}
+
+	cliOptions, _ := parseCLIArgs([]string{"-x", "--audio-format", "mp3", "-f", "-bestaudio", "-O", "id", "https://youtu.be/abc"}, pv.Catalog)
+	var got []string
+	for _, option := range pv.GetMergedOptions(cliOptions) {
+		got = append(got, shellJoin(option.Arguments())+" ← "+option.Source)
+	}
+
+	want := []string{
+		"-f -bestaudio ← command line",
+		"--print title ← Defaults",
+		"-x ← command line",
+		"--audio-format mp3 ← command line",
+		"-O id ← command line",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMergedOptions() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sources of options that don't come from a preset
+const (
+	sourceCommandLine = "command line"
+	sourceFormatList  = "format list"
+	sourceRateLimit   = "rate limit"
+	sourceBabago      = "babago"
+)
+
+// shellSafe matches arguments that don't need quoting in a POSIX shell
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+var (
+	commandPreviewStyle = lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("62")).
+				Padding(0, 1)
+
+	commandSourceStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+// buildYtDlpArgs returns the arguments for downloading url with the enabled options
+func buildYtDlpArgs(url string, options []Option) []string {
+	args := []string{url}
+	for _, option := range options {
+		if option.Enabled {
+			args = append(args, option.Arguments()...)
+		}
+	}
+	return args
+}
+
+// shellQuote quotes arg for a POSIX shell if needed
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes and joins args into a command line that can be pasted into a shell
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// commandLines formats argv as a shell command with one option per line, joined by backslashes
+func commandLines(command []string, options []Option) string {
+	lines := []string{shellJoin(command)}
+	for _, option := range options {
+		if option.Enabled {
+			lines = append(lines, "  "+shellJoin(option.Arguments()))
+		}
+	}
+	// Keep every progress flag on its own line next to its value
+	for _, arg := range progressArgs {
+		if strings.HasPrefix(arg, "-") {
+			lines = append(lines, "  "+shellQuote(arg))
+		} else {
+			lines[len(lines)-1] += " " + shellQuote(arg)
+		}
+	}
+	return strings.Join(lines, " \\\n")
+}
+
+// renderCommandPreview renders the exact command a queued download of url runs,
+// followed by every flag and where it came from, wrapped to width
+func renderCommandPreview(url string, options []Option, width int) string {
+	path, leading := ytDlpExecutable()
+	command := append(append([]string{path}, leading...), url)
+
+	s := focusedLabelStyle.Render("Command") + "\n" + commandLines(command, options) + "\n\n"
+
+	// Pad flags so the sources line up
+	flagWidth := 0
+	for _, option := range options {
+		if option.Enabled {
+			flagWidth = max(flagWidth, len(shellJoin(option.Arguments())))
+		}
+	}
+
+	s += focusedLabelStyle.Render("Sources") + "\n"
+	for _, option := range options {
+		if !option.Enabled {
+			continue
+		}
+		source := option.Source
+		if source == "" {
+			source = "unknown"
+		}
+		s += padRight(shellJoin(option.Arguments()), flagWidth) + "  " + commandSourceStyle.Render("← "+source) + "\n"
+	}
+	s += commandSourceStyle.Render("Progress and file reporting flags are added by " + sourceBabago)
+
+	// Border and padding take 4 columns
+	return commandPreviewStyle.Width(max(width-4, 20)).Render(s)
+}
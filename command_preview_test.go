@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBuildYtDlpArgs(t *testing.T) {
+	options := []Option{
+		{Flag: "-f best", Enabled: true},
+		{Flag: "--no-playlist", Enabled: false},
+		{Flag: "--embed-subs", Enabled: true},
+	}
+	got := buildYtDlpArgs("https://example.com/v", options)
+	want := []string{"https://example.com/v", "-f", "best", "--embed-subs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildYtDlpArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"--format=best", "--format=best"},
+		{"https://example.com/watch?v=1", "'https://example.com/watch?v=1'"},
+		{"%(title)s.%(ext)s", "'%(title)s.%(ext)s'"},
+		{"it's", `'it'\''s'`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.arg); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestCommandLines(t *testing.T) {
+	options := []Option{
+		{Flag: "-o %(title)s.%(ext)s", Enabled: true},
+		{Flag: "--no-playlist", Enabled: false},
+	}
+	lines := strings.Split(commandLines([]string{"yt-dlp", "https://example.com/v"}, options), " \\\n")
+
+	if lines[0] != "yt-dlp https://example.com/v" {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if lines[1] != "  -o '%(title)s.%(ext)s'" {
+		t.Errorf("option line = %q", lines[1])
+	}
+	for _, line := range lines[1:] {
+		if strings.Contains(line, "--no-playlist") {
+			t.Errorf("disabled option shown: %q", line)
+		}
+		if !strings.HasPrefix(line, "  -") {
+			t.Errorf("line doesn't start with a flag: %q", line)
+		}
+	}
+	flags := 0
+	for _, arg := range progressArgs {
+		if strings.HasPrefix(arg, "-") {
+			flags++
+		}
+	}
+	if len(lines) != 2+flags {
+		t.Errorf("got %d lines, want the command, one option and %d progress flags", len(lines), flags)
+	}
+}
+
+func TestOptionSources(t *testing.T) {
+	pv := PresetsView{Presets: []Preset{
+		{Name: "Best", Active: true, Options: []Option{{Flag: "-f best", Enabled: true}, {Flag: "--embed-subs", Enabled: true}}},
+		{Name: "Audio", Active: true, Options: []Option{{Flag: "-f bestaudio", Enabled: true}}},
+		{Name: "Inactive", Active: false, Options: []Option{{Flag: "--no-playlist", Enabled: true}}},
+	}}
+
+	got := make(map[string]string)
+	for _, option := range pv.GetMergedOptions(cliOptions([]string{"--embed-subs", "--proxy", "socks5://localhost"})) {
+		got[option.Flag] = option.Source
+	}
+	want := map[string]string{
+		"-f bestaudio":               "Audio",
+		"--embed-subs":               sourceCommandLine,
+		"--proxy socks5://localhost": sourceCommandLine,
+	}
+	if !reflect.DeepEqual(got, want) {
+		flags := make([]string, 0, len(got))
+		for flag, source := range got {
+			flags = append(flags, flag+" ← "+source)
+		}
+		sort.Strings(flags)
+		t.Errorf("GetMergedOptions() sources = %q, want %v", flags, want)
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -12,12 +15,33 @@ type HistoryConfig struct {
 	Names []string `json:"names"`
 }
 
+// Settings represents application-wide preferences
+type Settings struct {
+	Workers    int    `json:"workers"`     // Number of downloads running at the same time
+	Retries    int    `json:"retries"`     // Automatic retries after network errors or rate limiting
+	RetryDelay int    `json:"retry_delay"` // Seconds before the first retry, doubled for every further one
+	Archive    bool   `json:"archive"`     // Record downloads in the global download archive
+	RateLimit  string `json:"rate_limit"`  // Bandwidth shared by all downloads, e.g. "5M"; empty for none
+	// DomainLimits caps downloads running at the same time per domain, e.g. {"youtube.com": 2}
+	DomainLimits map[string]int `json:"domain_limits,omitempty"`
+	// YtDlpPath and YtDlpArgs run yt-dlp, e.g. "python" with ["-m", "yt_dlp"]
+	YtDlpPath       string   `json:"ytdlp_path"`
+	YtDlpArgs       []string `json:"ytdlp_args,omitempty"`
+	MinYtDlpVersion string   `json:"min_ytdlp_version"` // Warn when yt-dlp is older, e.g. "2024.08.06"
+	// Hooks run after every successful download, after the hooks of its presets
+	Hooks []Hook `json:"hooks,omitempty"`
+}
+
 // ConfigData represents the complete application configuration
 type ConfigData struct {
-	History HistoryConfig `json:"history"`
-	Presets []Preset      `json:"presets"`
+	History  HistoryConfig `json:"history"`
+	Presets  []Preset      `json:"presets"`
+	Settings Settings      `json:"settings"`
 }
 
+// appSettings holds the settings loaded at startup
+var appSettings = GetDefaultSettings()
+
 // getConfigDir returns the config directory path
 func getConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -68,36 +92,50 @@ func SaveConfig(urls []string, names []string, presets []Preset) error {
 			URLs:  urls,
 			Names: names,
 		},
-		Presets: presets,
+		Presets:  presets,
+		Settings: appSettings,
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
+	// Keep > and & readable in output templates and hooks
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
 		return err
 	}
 
-	return os.WriteFile(filePath, data, 0644)
+	return os.WriteFile(filePath, data.Bytes(), 0644)
 }
 
-// LoadConfig loads the complete application configuration
-func LoadConfig() ([]string, []string, []Preset, error) {
+// readConfigData reads the raw configuration file, returning an empty config if there's none.
+// Settings missing from the file keep their default values.
+func readConfigData() (ConfigData, error) {
+	config := ConfigData{Settings: GetDefaultSettings()}
+
 	filePath, err := getConfigFilePath()
 	if err != nil {
-		return []string{}, []string{}, []Preset{}, err
+		return config, err
 	}
 
 	// If file doesn't exist, return empty config
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return []string{}, []string{}, []Preset{}, nil
+		return config, nil
 	}
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return []string{}, []string{}, []Preset{}, err
+		return config, err
 	}
 
-	var config ConfigData
-	if err := json.Unmarshal(data, &config); err != nil {
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
+
+// LoadConfig loads the complete application configuration
+func LoadConfig() ([]string, []string, []Preset, error) {
+	config, err := readConfigData()
+	if err != nil {
 		return []string{}, []string{}, []Preset{}, err
 	}
 
@@ -115,6 +153,47 @@ func LoadConfig() ([]string, []string, []Preset, error) {
 	return config.History.URLs, config.History.Names, config.Presets, nil
 }
 
+// LoadSettings loads application settings, filling in defaults for missing values.
+// Invalid settings are reported in the error, all settings are loaded anyway.
+func LoadSettings() (Settings, error) {
+	settings := GetDefaultSettings()
+
+	config, err := readConfigData()
+	if err != nil {
+		return settings, err
+	}
+
+	if config.Settings.Workers > 0 {
+		settings.Workers = config.Settings.Workers
+	}
+	if config.Settings.Retries >= 0 {
+		settings.Retries = config.Settings.Retries
+	}
+	if config.Settings.RetryDelay > 0 {
+		settings.RetryDelay = config.Settings.RetryDelay
+	}
+	settings.Archive = config.Settings.Archive
+	// An invalid rate limit is kept as written, so saving the config doesn't erase it
+	settings.RateLimit = config.Settings.RateLimit
+	var problems []error
+	if _, err := parseRate(settings.RateLimit); err != nil {
+		problems = append(problems, fmt.Errorf("settings.rate_limit: %w, downloads aren't rate limited", err))
+	}
+	domainLimits, err := normalizeDomainLimits(config.Settings.DomainLimits)
+	if err != nil {
+		problems = append(problems, fmt.Errorf("settings.domain_limits: %w", err))
+	}
+	settings.DomainLimits = domainLimits
+	if config.Settings.YtDlpPath != "" {
+		settings.YtDlpPath = config.Settings.YtDlpPath
+	}
+	settings.YtDlpArgs = config.Settings.YtDlpArgs
+	settings.MinYtDlpVersion = config.Settings.MinYtDlpVersion
+	settings.Hooks = config.Settings.Hooks
+
+	return settings, errors.Join(problems...)
+}
+
 // AutoSaveConfig is a convenience function for saving complete config
 func AutoSaveConfig(uv *URLView, pv *PresetsView) {
 	if err := SaveConfig(uv.URLHistory, uv.HistoryNames, pv.Presets); err != nil {
@@ -124,6 +203,19 @@ func AutoSaveConfig(uv *URLView, pv *PresetsView) {
 	}
 }
 
+// GetDefaultSettings returns the default application settings
+func GetDefaultSettings() Settings {
+	return Settings{
+		Workers:    3,
+		Retries:    3,
+		RetryDelay: 10,
+		Archive:    true,
+		YtDlpPath:  "yt-dlp",
+		// First release printing after_move paths and progress template fields babago relies on
+		MinYtDlpVersion: "2023.03.04",
+	}
+}
+
 // GetDefaultPresets returns the default preset configuration
 func GetDefaultPresets() []Preset {
 	return []Preset{
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestConfig points HOME at a temporary directory holding config as config.json
+func writeTestConfig(t *testing.T, config string) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configDir := filepath.Join(home, ".config", "babago")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSettingsKeepsInvalidRateLimit(t *testing.T) {
+	writeTestConfig(t, `{
+  "settings": {
+    "workers": 2,
+    "rate_limit": "5 megs",
+    "archive": true
+  }
+}`)
+
+	settings, err := LoadSettings()
+	if err == nil {
+		t.Fatal("LoadSettings() didn't report the invalid rate limit")
+	}
+
+	want := GetDefaultSettings()
+	want.Workers = 2
+	want.RateLimit = "5 megs"
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("LoadSettings() = %+v, want %+v", settings, want)
+	}
+
+	// Saving the config again must keep the setting for the user to fix
+	previous := appSettings
+	appSettings = settings
+	defer func() { appSettings = previous }()
+	if err := SaveConfig(nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadSettings()
+	if err == nil {
+		t.Error("LoadSettings() after saving didn't report the invalid rate limit")
+	}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("LoadSettings() after saving = %+v, want %+v", saved, want)
+	}
+}
+
+func TestLoadSettingsNormalizesDomainLimits(t *testing.T) {
+	writeTestConfig(t, `{
+  "settings": {
+    "domain_limits": {"www.youtube.com": 1, "youtube.com": 3, "Vimeo.com": 2}
+  }
+}`)
+
+	settings, err := LoadSettings()
+	if err == nil {
+		t.Error("LoadSettings() didn't report the duplicate domain")
+	}
+	want := map[string]int{"youtube.com": 1, "vimeo.com": 2}
+	if !reflect.DeepEqual(settings.DomainLimits, want) {
+		t.Errorf("DomainLimits = %v, want %v", settings.DomainLimits, want)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	conflictAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+	conflictWinnerStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	conflictShadowedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Strikethrough(true)
+)
+
+// ConflictView lists flags set by several active presets and which preset wins
+type ConflictView struct {
+	Presets   []string // Active presets, lowest priority first
+	Conflicts []OptionConflict
+}
+
+// NewConflictView creates a new ConflictView instance
+func NewConflictView() ConflictView {
+	return ConflictView{}
+}
+
+// SetPresets computes the conflicts between the active presets
+func (cv *ConflictView) SetPresets(pv PresetsView) {
+	cv.Presets = pv.ActivePresetNames()
+	cv.Conflicts = pv.Conflicts()
+}
+
+// View renders the ConflictView
+func (cv ConflictView) View() string {
+	s := presetsTitleStyle.Render("Conflicts") + "\n\n"
+
+	faint := lipgloss.NewStyle().Faint(true)
+	if len(cv.Presets) == 0 {
+		s += "No active presets."
+		return conflictAppStyle.Render(s)
+	}
+	s += faint.Render("Priority: "+strings.Join(cv.Presets, " < ")) + "\n\n"
+
+	if len(cv.Conflicts) == 0 {
+		s += "No conflicts, every flag is set by a single preset."
+		return conflictAppStyle.Render(s)
+	}
+
+	// Pad flags so the presets line up
+	width := 0
+	for _, conflict := range cv.Conflicts {
+		width = max(width, len(shellJoin(conflict.Winner.Arguments())))
+		for _, option := range conflict.Shadowed {
+			width = max(width, len(shellJoin(option.Arguments())))
+		}
+	}
+
+	for _, conflict := range cv.Conflicts {
+		s += focusedLabelStyle.Render(conflict.Key) + "\n"
+		s += "  " + conflictWinnerStyle.Render("✓ "+padRight(shellJoin(conflict.Winner.Arguments()), width)) +
+			"  " + faint.Render("← "+conflict.Winner.Source) + "\n"
+		// Most recently overridden first
+		for i := len(conflict.Shadowed) - 1; i >= 0; i-- {
+			option := conflict.Shadowed[i]
+			s += "  " + conflictShadowedStyle.Render("✗ "+padRight(shellJoin(option.Arguments()), width)) +
+				"  " + faint.Render("← "+option.Source+" (shadowed)") + "\n"
+		}
+	}
+	summary := fmt.Sprintf("%d flags are set more than once", len(cv.Conflicts))
+	if len(cv.Conflicts) == 1 {
+		summary = "1 flag is set more than once"
+	}
+	s += "\n" + faint.Render(summary+", presets further down the list win")
+
+	return conflictAppStyle.Render(s)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// urlHost returns the lowercase host of rawURL without port and "www." prefix,
+// or an empty string if it can't be parsed
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return normalizeDomain(parsed.Hostname())
+}
+
+// normalizeDomain lowercases a domain and drops a trailing dot and "www." prefix
+func normalizeDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// normalizeDomainLimits normalizes the domains of configured limits. Domains that turn out
+// to be the same, like "www.youtube.com" and "youtube.com", keep the lowest limit and are
+// reported in the error.
+func normalizeDomainLimits(limits map[string]int) (map[string]int, error) {
+	if len(limits) == 0 {
+		return limits, nil
+	}
+
+	normalized := make(map[string]int, len(limits))
+	duplicates := make(map[string]bool)
+	for domain, limit := range limits {
+		domain = normalizeDomain(domain)
+		if previous, ok := normalized[domain]; ok {
+			duplicates[domain] = true
+			// A limit of 0 or less doesn't limit anything
+			if previous > 0 && (limit <= 0 || previous < limit) {
+				limit = previous
+			}
+		}
+		normalized[domain] = limit
+	}
+
+	if len(duplicates) == 0 {
+		return normalized, nil
+	}
+	var domains []string
+	for domain := range duplicates {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return normalized, fmt.Errorf("%s listed more than once, using the lowest limit", strings.Join(domains, ", "))
+}
+
+// hostMatches reports whether host is domain or one of its subdomains
+func hostMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// domainLimit returns the most specific configured domain covering host and its limit,
+// or an empty domain if host isn't limited. DomainLimits has to be normalized,
+// so exactly one domain of each length can match.
+func (q *Queue) domainLimit(host string) (string, int) {
+	var limitedDomain string
+	limit := 0
+	for domain, domainLimit := range q.DomainLimits {
+		if host != "" && hostMatches(host, domain) && len(domain) > len(limitedDomain) {
+			limitedDomain = domain
+			limit = domainLimit
+		}
+	}
+	return limitedDomain, limit
+}
+
+// domainAvailable reports whether the per-domain limit allows another job for rawURL to start
+func (q *Queue) domainAvailable(rawURL string) bool {
+	domain, limit := q.domainLimit(urlHost(rawURL))
+	if domain == "" || limit <= 0 {
+		return true
+	}
+
+	active := 0
+	for _, job := range q.Jobs {
+		if (job.State == JobRunning || job.State == JobPaused) && hostMatches(urlHost(job.URL), domain) {
+			active++
+		}
+	}
+	return active < limit
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURLHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.YouTube.com/watch?v=abc", "youtube.com"},
+		{"https://music.youtube.com:443/watch", "music.youtube.com"},
+		{"http://example.com./video", "example.com"},
+		{"not a url", ""},
+	}
+
+	for _, test := range tests {
+		if got := urlHost(test.url); got != test.want {
+			t.Errorf("urlHost(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeDomainLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  map[string]int
+		want    map[string]int
+		wantErr bool
+	}{
+		{"none", nil, nil, false},
+		{"normalized", map[string]int{"WWW.YouTube.com.": 2, "vimeo.com": 1}, map[string]int{"youtube.com": 2, "vimeo.com": 1}, false},
+		{"duplicates keep the lowest", map[string]int{"www.youtube.com": 1, "youtube.com": 3}, map[string]int{"youtube.com": 1}, true},
+		{"no limit loses", map[string]int{"www.youtube.com": 0, "youtube.com": 3}, map[string]int{"youtube.com": 3}, true},
+	}
+
+	for _, test := range tests {
+		// Map order varies between runs, so check several times
+		for i := 0; i < 10; i++ {
+			got, err := normalizeDomainLimits(test.limits)
+			if (err != nil) != test.wantErr {
+				t.Errorf("%s: error = %v, want error %v", test.name, err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%s: normalizeDomainLimits() = %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDomainLimit(t *testing.T) {
+	queue := NewQueue(5)
+	queue.DomainLimits = map[string]int{"youtube.com": 2, "music.youtube.com": 1, "vimeo.com": 1}
+
+	tests := []struct {
+		host       string
+		wantDomain string
+		wantLimit  int
+	}{
+		{"youtube.com", "youtube.com", 2},
+		{"m.youtube.com", "youtube.com", 2},
+		{"music.youtube.com", "music.youtube.com", 1},
+		{"notyoutube.com", "", 0},
+		{"example.com", "", 0},
+		{"", "", 0},
+	}
+
+	for _, test := range tests {
+		domain, limit := queue.domainLimit(test.host)
+		if domain != test.wantDomain || limit != test.wantLimit {
+			t.Errorf("domainLimit(%q) = %q, %d, want %q, %d", test.host, domain, limit, test.wantDomain, test.wantLimit)
+		}
+	}
+}
+
+func TestDomainAvailable(t *testing.T) {
+	queue := NewQueue(5)
+	queue.DomainLimits = map[string]int{"youtube.com": 2}
+	for _, url := range []string{"https://www.youtube.com/watch?v=1", "https://m.youtube.com/watch?v=2", "https://vimeo.com/3"} {
+		queue.Enqueue(url, nil, nil).State = JobRunning
+	}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://youtube.com/watch?v=4", false},
+		{"https://vimeo.com/5", true},
+		{"https://example.com/6", true},
+	}
+	for _, test := range tests {
+		if got := queue.domainAvailable(test.url); got != test.want {
+			t.Errorf("domainAvailable(%q) = %v, want %v", test.url, got, test.want)
+		}
+	}
+
+	// A finished job frees its slot
+	queue.Jobs[0].State = JobDone
+	if !queue.domainAvailable("https://youtube.com/watch?v=4") {
+		t.Error("finished job still counts against the domain limit")
+	}
+}
@@ -1,135 +1,352 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"io/fs"
+	"io"
+	"math"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// ytDlpFinishedMsg is sent when yt-dlp process finishes
-type ytDlpFinishedMsg struct {
-	err               error
-	url               string
-	downloadStartTime time.Time
+// progressPrefix marks the machine-readable lines requested via --progress-template
+const progressPrefix = "[babago]"
+
+// filePrefix marks the lines carrying the final path of a downloaded file
+const filePrefix = "[babago-file]"
+
+// archivePrefix marks the lines carrying the download archive key of a downloaded file
+const archivePrefix = "[babago-archive]"
+
+// titlePrefix marks the lines carrying the title of a downloaded file
+const titlePrefix = "[babago-title]"
+
+// reportTemplates report the final path, archive key and title of every file once it has been moved into place
+var reportTemplates = []string{
+	"after_move:" + filePrefix + "%(filepath)s",
+	"after_move:" + archivePrefix + "%(extractor_key)s %(id)s",
+	"after_move:" + titlePrefix + "%(title)s",
+}
+
+// progressArgs makes yt-dlp print one parseable progress line per update
+// and the reportTemplates of every file.
+// --print implies --quiet, so --progress keeps the progress lines coming.
+var progressArgs = append([]string{
+	"--newline",
+	"--progress",
+	"--progress-template",
+	"download:" + progressPrefix + "%(progress._percent_str)s|%(progress._speed_str)s|%(progress._eta_str)s|%(progress.filename)s",
+}, reportArgs("--print")...)
+
+// reportArgs passes every report template to yt-dlp with flag, followed by extra arguments such as a file
+func reportArgs(flag string, extra ...string) []string {
+	var args []string
+	for _, template := range reportTemplates {
+		args = append(append(args, flag, template), extra...)
+	}
+	return args
 }
 
-// ExecuteYtDlpCmd uruchamia yt-dlp używając tea.ExecProcess
-func ExecuteYtDlpCmd(url string, options []Option) tea.Cmd {
-	downloadStartTime := time.Now()
+// errNoProcess is returned when signalling a job that has no running process
+var errNoProcess = errors.New("no running yt-dlp process")
 
-	// Buduj argumenty komendy
-	args := []string{url}
+// maxOutputLines limits how much yt-dlp output is kept per download
+const maxOutputLines = 200
 
-	// Dodaj włączone opcje
-	for _, option := range options {
-		if option.Enabled {
-			flagParts := strings.Fields(option.Flag)
-			args = append(args, flagParts...)
-		}
+// ExecuteYtDlpCmd starts yt-dlp as a piped child process for a queued job.
+// Progress is streamed to events as DownloadMsg values tagged with jobID.
+func ExecuteYtDlpCmd(jobID int, url string, options []Option, events chan<- DownloadMsg) (*exec.Cmd, error) {
+	// Build command arguments
+	args := append(buildYtDlpArgs(url, options), progressArgs...)
+
+	// Log command
+	logToFile("Executing: " + ytDlpCommandLine(args...))
+
+	cmd := ytDlpCommand(args...)
+	setProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
 
-	// Loguj komendę
-	logToFile("Executing: yt-dlp " + strings.Join(args, " "))
+	progress := DownloadProgress{
+		URL:       url,
+		State:     DownloadRunning,
+		StartedAt: time.Now(),
+	}
 
-	// Utwórz exec.Cmd
-	cmd := exec.Command("yt-dlp", args...)
+	go func() {
+		// Merge stdout and stderr into a single stream of lines
+		lines := make(chan string)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go scanLines(stdout, lines, &wg)
+		go scanLines(stderr, lines, &wg)
+		go func() {
+			wg.Wait()
+			close(lines)
+		}()
 
-	// Użyj tea.ExecProcess żeby uruchomić komendę w terminalu
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
-		return ytDlpFinishedMsg{
-			err:               err,
-			url:               url,
-			downloadStartTime: downloadStartTime,
+		for line := range lines {
+			parseProgressLine(&progress, line)
+			events <- DownloadMsg{JobID: jobID, Progress: progress, Line: line}
 		}
-	})
+
+		// Pipes are drained, so it's safe to wait for the process now
+		err := cmd.Wait()
+		events <- DownloadMsg{JobID: jobID, Progress: progress, Done: true, Error: err}
+	}()
+
+	return cmd, nil
 }
 
-// findDownloadedFile znajduje najnowszy plik pobrany po określonym czasie
-func findDownloadedFile(afterTime time.Time) (string, error) {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return "", err
+// scanLines sends every line read from r to lines
+func scanLines(r io.Reader, lines chan<- string, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines <- scanner.Text()
 	}
+}
 
-	var newestFile string
-	var newestTime time.Time
+// waitForDownloadMsg waits for the next message of any running download
+func waitForDownloadMsg(events <-chan DownloadMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
 
-	err = filepath.WalkDir(currentDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil // Ignoruj błędy i kontynuuj
-		}
+// parseProgressLine updates progress with information found in a single yt-dlp output line
+func parseProgressLine(progress *DownloadProgress, line string) {
+	line = strings.TrimSpace(line)
 
-		// Sprawdź tylko pliki, nie katalogi
-		if d.IsDir() {
-			return nil
+	// Our own --progress-template line: percent|speed|eta|filename
+	if strings.HasPrefix(line, progressPrefix) {
+		fields := strings.SplitN(strings.TrimPrefix(line, progressPrefix), "|", 4)
+		if len(fields) != 4 {
+			return
 		}
-
-		// Ignoruj pliki systemowe i konfiguracyjne
-		if strings.HasPrefix(d.Name(), ".") || strings.HasSuffix(d.Name(), ".log") {
-			return nil
+		progress.Percentage = cleanProgressField(fields[0])
+		progress.Speed = cleanProgressField(fields[1])
+		progress.ETA = cleanProgressField(fields[2])
+		if filename := cleanProgressField(fields[3]); filename != "" {
+			progress.Filename = filepath.Base(filename)
+			progress.addDestination(filename)
 		}
+		return
+	}
 
-		info, err := d.Info()
-		if err != nil {
-			return nil
+	// Errors and warnings
+	if message, ok := parseYtDlpMessage(line); ok {
+		progress.addMessage(message)
+		return
+	}
+
+	// Final path of a finished file
+	if strings.HasPrefix(line, filePrefix) {
+		path := strings.TrimPrefix(line, filePrefix)
+		if path != "" && path != "NA" {
+			progress.Files = append(progress.Files, path)
+			progress.Filename = filepath.Base(path)
 		}
+		return
+	}
 
-		// Sprawdź czy plik został stworzony po czasie rozpoczęcia pobierania
-		if info.ModTime().After(afterTime) {
-			if newestFile == "" || info.ModTime().After(newestTime) {
-				newestFile = d.Name()
-				newestTime = info.ModTime()
-			}
+	// Archive key of a finished file
+	if strings.HasPrefix(line, archivePrefix) {
+		fields := strings.Fields(strings.TrimPrefix(line, archivePrefix))
+		if len(fields) == 2 && fields[0] != "NA" && fields[1] != "NA" {
+			progress.ArchiveKeys = append(progress.ArchiveKeys, archiveKey(fields[0], fields[1]))
 		}
+		return
+	}
 
-		return nil
-	})
+	// Title of a finished file
+	if strings.HasPrefix(line, titlePrefix) {
+		progress.Titles = append(progress.Titles, strings.TrimPrefix(line, titlePrefix))
+		return
+	}
 
-	if err != nil {
-		return "", err
+	// Lines announcing the file yt-dlp is writing to
+	for _, prefix := range []string{
+		"[download] Destination: ",
+		"[ExtractAudio] Destination: ",
+		"[Merger] Merging formats into ",
+	} {
+		if strings.HasPrefix(line, prefix) {
+			filename := strings.Trim(strings.TrimPrefix(line, prefix), `"`)
+			progress.Filename = filepath.Base(filename)
+			progress.addDestination(filename)
+			return
+		}
 	}
 
-	if newestFile == "" {
-		return "", os.ErrNotExist
+	// File was downloaded before
+	if strings.HasPrefix(line, "[download] ") && strings.HasSuffix(line, " has already been downloaded") {
+		filename := strings.TrimSuffix(strings.TrimPrefix(line, "[download] "), " has already been downloaded")
+		progress.Filename = filepath.Base(filename)
+		progress.Percentage = "100%"
 	}
+}
 
-	return newestFile, nil
+// addDestination remembers a file yt-dlp writes to, so partial downloads can be cleaned up
+func (p *DownloadProgress) addDestination(path string) {
+	for _, destination := range p.Destinations {
+		if destination == path {
+			return
+		}
+	}
+	p.Destinations = append(p.Destinations, path)
 }
 
-// runYtDlpDirect executes yt-dlp directly in CLI mode (not through Bubble Tea)
-func runYtDlpDirect(url string, options []Option) {
-	// Build command arguments
-	args := []string{url}
+// cleanupPartialFiles removes the .part and fragment files left behind by an interrupted download
+func cleanupPartialFiles(destinations []string) {
+	for _, destination := range destinations {
+		candidates := []string{destination + ".part", destination + ".ytdl"}
+		fragments, _ := filepath.Glob(escapeGlob(destination) + ".part-Frag*")
+		candidates = append(candidates, fragments...)
 
-	// Add enabled options
-	for _, option := range options {
-		if option.Enabled {
-			flagParts := strings.Fields(option.Flag)
-			args = append(args, flagParts...)
+		for _, candidate := range candidates {
+			if err := os.Remove(candidate); err == nil {
+				logToFile("Removed partial file: " + candidate)
+			}
 		}
 	}
+}
+
+// escapeGlob escapes characters filepath.Glob treats specially, which are common in video titles
+func escapeGlob(path string) string {
+	replacer := strings.NewReplacer("[", "[[]", "*", "[*]", "?", "[?]")
+	return replacer.Replace(path)
+}
+
+// cleanProgressField trims a progress template field and drops yt-dlp's placeholders
+func cleanProgressField(field string) string {
+	field = strings.TrimSpace(field)
+	if field == "NA" || field == "Unknown" || field == "N/A" {
+		return ""
+	}
+	return field
+}
+
+// Percent returns the download percentage as a value between 0 and 1
+func (p DownloadProgress) Percent() float64 {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(p.Percentage, "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return math.Max(0, math.Min(value/100, 1))
+}
+
+// runYtDlpDirect executes yt-dlp directly in CLI mode (not through Bubble Tea),
+// passing extraArgs after the options
+func runYtDlpDirect(url string, options []Option, extraArgs ...string) error {
+	// Build command arguments
+	args := append(buildYtDlpArgs(url, options), extraArgs...)
 
 	// Log command
-	logToFile("Executing directly: yt-dlp " + strings.Join(args, " "))
-	fmt.Println("Executing: yt-dlp " + strings.Join(args, " "))
+	logToFile("Executing directly: " + ytDlpCommandLine(args...))
+	fmt.Println("Executing: " + ytDlpCommandLine(args...))
 
-	// Create and run command directly
-	cmd := exec.Command("yt-dlp", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Create command in its own process group so it can be stopped with its children
+	output := &destinationWriter{}
+	cmd := ytDlpCommand(args...)
+	setProcessGroup(cmd)
+	cmd.Stdout = io.MultiWriter(os.Stdout, output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, output)
 	cmd.Stdin = os.Stdin
 
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("Error executing yt-dlp: %v\n", err)
-		os.Exit(1)
+	// Handle Ctrl+C ourselves to stop yt-dlp and clean up after it
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			if message := output.LastError(); message != "" {
+				return fmt.Errorf("%s (%w)", message, err)
+			}
+			return err
+		}
+	case <-signals:
+		fmt.Println("\nInterrupted, stopping yt-dlp...")
+		if err := killProcessGroup(cmd); err != nil {
+			logToFile("Failed to stop yt-dlp: " + err.Error())
+		}
+		<-done
+		cleanupPartialFiles(output.Destinations())
+		os.Exit(130)
 	}
 
 	logToFile("yt-dlp completed successfully in CLI mode")
+	return nil
+}
+
+// destinationWriter collects the files and errors yt-dlp announces while its output is passed through
+type destinationWriter struct {
+	mu       sync.Mutex
+	line     []byte
+	progress DownloadProgress
+}
+
+// Write parses output line by line, treating carriage returns as line breaks
+func (w *destinationWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, b := range p {
+		if b == '\n' || b == '\r' {
+			parseProgressLine(&w.progress, string(w.line))
+			w.line = w.line[:0]
+			continue
+		}
+		w.line = append(w.line, b)
+	}
+	return len(p), nil
+}
+
+// Destinations returns the files seen so far
+func (w *destinationWriter) Destinations() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.progress.Destinations...)
+}
+
+// LastError returns the latest error yt-dlp printed, or an empty string
+func (w *destinationWriter) LastError() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.progress.Error
 }
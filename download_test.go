@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want DownloadProgress
+	}{
+		{
+			"progress template",
+			"[babago]  42.5%|  1.20MiB/s|00:13|/tmp/My Video [abc].webm",
+			DownloadProgress{Percentage: "42.5%", Speed: "1.20MiB/s", ETA: "00:13", Filename: "My Video [abc].webm"},
+		},
+		{
+			"unknown fields",
+			"[babago]   0.0%|Unknown|NA|/tmp/My Video [abc].webm",
+			DownloadProgress{Percentage: "0.0%", Filename: "My Video [abc].webm"},
+		},
+		{
+			"filename with a pipe",
+			"[babago] 10.0%|1.00MiB/s|00:09|/tmp/a|b.webm",
+			DownloadProgress{Percentage: "10.0%", Speed: "1.00MiB/s", ETA: "00:09", Filename: "a|b.webm"},
+		},
+		{
+			"incomplete progress template",
+			"[babago] 10.0%|1.00MiB/s",
+			DownloadProgress{},
+		},
+		{
+			"download destination",
+			"[download] Destination: /tmp/My Video [abc].f137.mp4",
+			DownloadProgress{Filename: "My Video [abc].f137.mp4"},
+		},
+		{
+			"merged formats",
+			`[Merger] Merging formats into "/tmp/My Video [abc].mkv"`,
+			DownloadProgress{Filename: "My Video [abc].mkv"},
+		},
+		{
+			"extracted audio",
+			"[ExtractAudio] Destination: /tmp/My Video [abc].mp3",
+			DownloadProgress{Filename: "My Video [abc].mp3"},
+		},
+		{
+			"downloaded before",
+			"[download] /tmp/My Video [abc].webm has already been downloaded",
+			DownloadProgress{Filename: "My Video [abc].webm", Percentage: "100%"},
+		},
+		{
+			"other output",
+			"[youtube] abc: Downloading webpage",
+			DownloadProgress{},
+		},
+	}
+
+	for _, test := range tests {
+		var progress DownloadProgress
+		parseProgressLine(&progress, test.line)
+		got := DownloadProgress{
+			Filename:   progress.Filename,
+			Percentage: progress.Percentage,
+			Speed:      progress.Speed,
+			ETA:        progress.ETA,
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: parseProgressLine(%q) = %+v, want %+v", test.name, test.line, got, test.want)
+		}
+	}
+}
+
+func TestDownloadProgressPercent(t *testing.T) {
+	tests := []struct {
+		percentage string
+		want       float64
+	}{
+		{"42.5%", 0.425},
+		{"100%", 1},
+		{"120%", 1},
+		{"", 0},
+		{"NA", 0},
+	}
+
+	for _, test := range tests {
+		progress := DownloadProgress{Percentage: test.percentage}
+		if got := progress.Percent(); got != test.want {
+			t.Errorf("Percent() of %q = %v, want %v", test.percentage, got, test.want)
+		}
+	}
+}
+
+func TestParseProgressLineFiles(t *testing.T) {
+	lines := []string{
+		"[download] Destination: /tmp/clip.f137.mp4",
+		"[babago] 50.0%|1.00MiB/s|00:01|/tmp/clip.f137.mp4",
+		"[download] Destination: /tmp/clip.f140.m4a",
+		`[Merger] Merging formats into "/tmp/clip.mp4"`,
+		"[babago-file]/videos/clip.mp4",
+		"[babago-file]NA",
+	}
+
+	var progress DownloadProgress
+	for _, line := range lines {
+		parseProgressLine(&progress, line)
+	}
+
+	wantDestinations := []string{"/tmp/clip.f137.mp4", "/tmp/clip.f140.m4a", "/tmp/clip.mp4"}
+	if !reflect.DeepEqual(progress.Destinations, wantDestinations) {
+		t.Errorf("Destinations = %q, want %q", progress.Destinations, wantDestinations)
+	}
+	if want := []string{"/videos/clip.mp4"}; !reflect.DeepEqual(progress.Files, want) {
+		t.Errorf("Files = %q, want %q", progress.Files, want)
+	}
+	if progress.Filename != "clip.mp4" {
+		t.Errorf("Filename = %q, want %q", progress.Filename, "clip.mp4")
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	downloadsAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+	downloadsTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFDF5")).
+				Background(lipgloss.Color("#25A065")).
+				Padding(0, 1)
+
+	downloadsHeaderStyle = lipgloss.NewStyle().Faint(true)
+
+	warningBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(lipgloss.Color("11")).
+				Padding(0, 1)
+
+	hookFailedBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("15")).
+				Background(lipgloss.Color("9")).
+				Padding(0, 1)
+
+	downloadsSelectedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("170")). // Fuchsia/magenta color used for list selection
+				Bold(true)
+
+	jobStateStyles = map[JobState]lipgloss.Style{
+		JobQueued:   lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		JobRunning:  lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+		JobPaused:   lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+		JobRetrying: lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		JobDone:     lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+		JobFailed:   lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		JobCanceled: lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+	}
+)
+
+// maxListedWarnings limits how many warnings are shown in the job details
+const maxListedWarnings = 3
+
+// Column widths of the jobs table
+const (
+	jobIDWidth      = 5
+	jobStateWidth   = 9
+	jobPercentWidth = 7
+	jobSpeedWidth   = 12
+	jobETAWidth     = 9
+	jobPresetsWidth = 24
+)
+
+// NewDownloadsView creates a new DownloadsView instance
+func NewDownloadsView(queue *Queue, keys keyMap) DownloadsView {
+	progressBar := progress.New(progress.WithDefaultGradient(), progress.WithoutPercentage())
+	progressBar.Width = 20
+
+	scheduleInput := textinput.New()
+	scheduleInput.Placeholder = "HH:MM or HH:MM-HH:MM"
+	scheduleInput.CharLimit = 11
+	scheduleInput.Width = 20
+
+	return DownloadsView{
+		Queue:         queue,
+		Keys:          keys,
+		Cursor:        0,
+		Offset:        0,
+		ProgressBar:   progressBar,
+		ScheduleInput: scheduleInput,
+	}
+}
+
+// Update handles input for the DownloadsView
+func (dv *DownloadsView) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		dv.Height = msg.Height
+	case tea.KeyMsg:
+		if dv.Scheduling {
+			return dv.updateSchedule(msg)
+		}
+
+		switch {
+		case key.Matches(msg, dv.Keys.Up):
+			if dv.Cursor > 0 {
+				dv.Cursor--
+			}
+		case key.Matches(msg, dv.Keys.Down):
+			if dv.Cursor < len(dv.Queue.Jobs)-1 {
+				dv.Cursor++
+			}
+		case key.Matches(msg, dv.Keys.Cancel):
+			if job := dv.SelectedJob(); job != nil {
+				dv.setStatus(dv.Queue.Cancel(job), fmt.Sprintf("Canceling download %d", job.ID))
+				// Canceling a queued job may free the way for the next one
+				dv.Queue.Schedule()
+			}
+		case key.Matches(msg, dv.Keys.Pause):
+			if job := dv.SelectedJob(); job != nil {
+				dv.setStatus(dv.Queue.Pause(job), fmt.Sprintf("Paused download %d", job.ID))
+			}
+		case key.Matches(msg, dv.Keys.Resume):
+			if job := dv.SelectedJob(); job != nil {
+				dv.setStatus(dv.Queue.Resume(job), fmt.Sprintf("Resumed download %d", job.ID))
+			}
+		case key.Matches(msg, dv.Keys.Schedule):
+			if job := dv.SelectedJob(); job != nil && job.State != JobDone && job.State != JobFailed && job.State != JobCanceled {
+				dv.ScheduleInput.SetValue(job.ScheduleText())
+				dv.ScheduleInput.CursorEnd()
+				dv.ScheduleInput.Focus()
+				dv.Scheduling = true
+				dv.Status = ""
+			}
+		}
+		dv.scrollToCursor()
+	}
+
+	return nil
+}
+
+// updateSchedule handles input while the schedule of the selected job is edited
+func (dv *DownloadsView) updateSchedule(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		job := dv.SelectedJob()
+		if job == nil {
+			break
+		}
+		startAt, window, err := parseSchedule(dv.ScheduleInput.Value(), time.Now())
+		if err != nil {
+			dv.setStatus(err, "")
+			return nil
+		}
+		job.StartAt = startAt
+		job.Window = window
+		dv.Queue.Save()
+		if description := job.ScheduleDescription(); description != "" {
+			dv.setStatus(nil, fmt.Sprintf("Download %d: %s", job.ID, strings.ToLower(description[:1])+description[1:]))
+		} else {
+			dv.setStatus(nil, fmt.Sprintf("Download %d: schedule cleared", job.ID))
+		}
+		// Stop or start jobs according to the new schedule
+		dv.Queue.Schedule()
+	case "esc":
+	default:
+		var cmd tea.Cmd
+		dv.ScheduleInput, cmd = dv.ScheduleInput.Update(msg)
+		return cmd
+	}
+
+	dv.Scheduling = false
+	dv.ScheduleInput.Blur()
+	return nil
+}
+
+// setStatus shows the result of a dashboard action
+func (dv *DownloadsView) setStatus(err error, success string) {
+	if err != nil {
+		dv.Status = jobStateStyles[JobFailed].Render(err.Error())
+		return
+	}
+	dv.Status = success
+}
+
+// visibleRows returns how many jobs fit on the screen
+func (dv DownloadsView) visibleRows() int {
+	// Leave room for title, header, details and help
+	return max(dv.Height-14, 3)
+}
+
+// scrollToCursor keeps the selected job inside the visible window
+func (dv *DownloadsView) scrollToCursor() {
+	rows := dv.visibleRows()
+	if dv.Cursor < dv.Offset {
+		dv.Offset = dv.Cursor
+	} else if dv.Cursor >= dv.Offset+rows {
+		dv.Offset = dv.Cursor - rows + 1
+	}
+}
+
+// SelectedJob returns the job under the cursor, or nil if the queue is empty
+func (dv DownloadsView) SelectedJob() *Job {
+	if dv.Cursor < 0 || dv.Cursor >= len(dv.Queue.Jobs) {
+		return nil
+	}
+	return dv.Queue.Jobs[dv.Cursor]
+}
+
+// View renders the DownloadsView
+func (dv DownloadsView) View() string {
+	var s string
+	s += downloadsTitleStyle.Render("Downloads") + "\n\n"
+
+	if len(dv.Queue.Jobs) == 0 {
+		s += "No downloads yet. Paste a URL and press Enter to queue one!"
+		return downloadsAppStyle.Render(s)
+	}
+
+	// Table header
+	header := "  " + padCell("#", jobIDWidth) + padCell("State", jobStateWidth) +
+		strings.Repeat(" ", dv.ProgressBar.Width+1) + padCell("Done", jobPercentWidth) +
+		padCell("Speed", jobSpeedWidth) + padCell("ETA", jobETAWidth) +
+		padCell("Presets", jobPresetsWidth) + "File"
+	s += downloadsHeaderStyle.Render(header) + "\n"
+
+	// Job rows
+	end := min(dv.Offset+dv.visibleRows(), len(dv.Queue.Jobs))
+	for i := dv.Offset; i < end; i++ {
+		s += dv.renderJobRow(dv.Queue.Jobs[i], i == dv.Cursor) + "\n"
+	}
+	if len(dv.Queue.Jobs) > end-dv.Offset {
+		s += downloadsHeaderStyle.Render(fmt.Sprintf("  %d-%d of %d", dv.Offset+1, end, len(dv.Queue.Jobs))) + "\n"
+	}
+
+	// Details of the selected job
+	if job := dv.SelectedJob(); job != nil {
+		s += "\n" + dv.renderJobDetails(job)
+	}
+
+	if dv.Scheduling {
+		s += "\n\n" + focusedLabelStyle.Render("Schedule:") + " " + dv.ScheduleInput.View() + "\n"
+		s += downloadsHeaderStyle.Render("HH:MM: start at • HH:MM-HH:MM: only run in window • empty: no schedule • Enter: save • Esc: cancel")
+	} else if dv.Status != "" {
+		s += "\n\n" + dv.Status
+	}
+
+	return downloadsAppStyle.Render(s)
+}
+
+// renderJobRow renders a single row of the jobs table
+func (dv DownloadsView) renderJobRow(job *Job, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = downloadsSelectedStyle.Render("› ")
+	}
+
+	percent := job.Progress.Percent()
+	percentText := job.Progress.Percentage
+	if job.State == JobDone {
+		percent = 1
+		percentText = "100%"
+	}
+
+	filename := job.Progress.Filename
+	if filename == "" {
+		filename = job.Title
+	}
+	if filename == "" {
+		filename = job.URL
+	}
+
+	eta := job.Progress.ETA
+	stateText := job.State.String()
+	stateStyle := jobStateStyles[job.State]
+	switch {
+	case job.Waiting(time.Now()):
+		// Count down to the start of the job's schedule
+		stateText = "waiting"
+		stateStyle = jobStateStyles[JobPaused]
+		eta = formatDuration(time.Until(job.ReadyAt(time.Now())).Seconds())
+	case job.State == JobRetrying:
+		// Count down to the next attempt
+		eta = formatDuration(math.Max(time.Until(job.RetryAt).Seconds(), 0))
+	case job.State == JobFailed:
+		filename = "[" + job.Failure.String() + "] " + filename
+	case job.HooksRunning:
+		// The download is done, its hooks are still running
+		stateText = "hooks"
+		stateStyle = jobStateStyles[JobRunning]
+	}
+
+	if len(job.HookErrors) > 0 {
+		filename = hookFailedBadgeStyle.Render("✗ hook") + " " + filename
+	}
+
+	if warnings := len(job.Progress.Warnings); warnings > 0 {
+		filename = warningBadgeStyle.Render(fmt.Sprintf("⚠ %d", warnings)) + " " + filename
+	}
+
+	state := stateStyle.Render(padCell(stateText, jobStateWidth))
+
+	return cursor +
+		padCell(fmt.Sprintf("%d", job.ID), jobIDWidth) +
+		state +
+		dv.ProgressBar.ViewAs(percent) + " " +
+		padCell(percentText, jobPercentWidth) +
+		padCell(job.Progress.Speed, jobSpeedWidth) +
+		padCell(eta, jobETAWidth) +
+		padCell(strings.Join(job.Presets, ", "), jobPresetsWidth) +
+		filename
+}
+
+// renderJobDetails renders extra information about the selected job
+func (dv DownloadsView) renderJobDetails(job *Job) string {
+	details := fmt.Sprintf("URL: %s", job.URL)
+	if job.Title != "" {
+		details = fmt.Sprintf("Title: %s\n", job.Title) + details
+	}
+	if len(job.Progress.Files) > 0 {
+		for _, file := range job.Progress.Files {
+			details += fmt.Sprintf("\nFile: %s", file)
+		}
+	} else if job.Progress.Filename != "" {
+		details += fmt.Sprintf("\nFile: %s", job.Progress.Filename)
+	}
+	if job.Progress.Error != "" {
+		details += "\n" + jobStateStyles[JobFailed].Render("Error: "+job.Progress.Error)
+	}
+	for i, warning := range job.Progress.Warnings {
+		if i == maxListedWarnings {
+			details += "\n" + downloadsHeaderStyle.Render(fmt.Sprintf("...and %d more warnings", len(job.Progress.Warnings)-maxListedWarnings))
+			break
+		}
+		details += "\n" + warningBadgeStyle.Render("Warning") + " " + warning.String()
+	}
+	if job.State == JobQueued && !dv.Queue.domainAvailable(job.URL) {
+		domain, limit := dv.Queue.domainLimit(urlHost(job.URL))
+		details += fmt.Sprintf("\nWaiting: %d downloads from %s are running already", limit, domain)
+	}
+	if schedule := job.ScheduleDescription(); schedule != "" {
+		details += "\nSchedule: " + schedule
+	}
+	if job.State == JobRunning && !math.IsInf(job.RateLimit, 1) && job.RateLimit > 0 {
+		details += fmt.Sprintf("\nRate limit: %s/s", formatRate(job.RateLimit))
+	}
+	if job.Attempts > 1 {
+		details += fmt.Sprintf("\nAttempts: %d", job.Attempts)
+	}
+	if job.HooksRunning {
+		details += fmt.Sprintf("\nRunning %d hooks", len(job.Hooks))
+	}
+	for _, hookError := range job.HookErrors {
+		details += "\n" + jobStateStyles[JobFailed].Render("Hook failed: "+hookError)
+	}
+	if len(job.Progress.Output) > 0 {
+		details += "\n" + downloadsHeaderStyle.Render(job.Progress.Output[len(job.Progress.Output)-1])
+	}
+	return details
+}
+
+// padCell truncates or pads text to exactly width characters plus a separating space
+func padCell(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		if width > 1 {
+			text = string(runes[:width-1]) + "…"
+		} else {
+			text = string(runes[:width])
+		}
+	}
+	return lipgloss.NewStyle().Width(width).Render(text) + " "
+}
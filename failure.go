@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// maxRetryDelay caps the exponential backoff between retries
+const maxRetryDelay = 10 * time.Minute
+
+// FailureKind classifies why a yt-dlp run failed
+type FailureKind int
+
+const (
+	FailureUnknown FailureKind = iota
+	FailureNetwork
+	FailureRateLimited
+	FailureGeoBlocked
+	FailureUnavailable
+	FailureLoginRequired
+	FailureMissingFFmpeg
+)
+
+// failurePatterns maps lowercase stderr fragments to the failure they indicate.
+// Checked in order, so more specific patterns come first.
+var failurePatterns = []struct {
+	kind     FailureKind
+	patterns []string
+}{
+	{FailureRateLimited, []string{"http error 429", "too many requests", "rate-limit", "rate limit"}},
+	{FailureMissingFFmpeg, []string{"ffmpeg not found", "ffprobe not found", "ffmpeg is not installed", "ffprobe and ffmpeg not found"}},
+	{FailureGeoBlocked, []string{"not available in your country", "geo restrict", "geo-restrict", "geoblocked", "uploader has not made this video available"}},
+	{FailureLoginRequired, []string{"sign in to confirm", "login required", "requires authentication", "use --cookies", "members-only", "join this channel", "account-authentication"}},
+	{FailureUnavailable, []string{"private video", "video unavailable", "has been removed", "been terminated", "no longer available", "does not exist", "http error 404", "http error 410"}},
+	{FailureNetwork, []string{"timed out", "connection reset", "connection refused", "connection aborted", "temporary failure in name resolution", "name or service not known", "network is unreachable", "unable to download webpage", "http error 5", "incompleteread", "remote end closed connection", "ssl:"}},
+}
+
+// String returns a short label for the failure kind
+func (k FailureKind) String() string {
+	switch k {
+	case FailureNetwork:
+		return "network error"
+	case FailureRateLimited:
+		return "rate limited (HTTP 429)"
+	case FailureGeoBlocked:
+		return "geo-blocked"
+	case FailureUnavailable:
+		return "private or removed"
+	case FailureLoginRequired:
+		return "login required"
+	case FailureMissingFFmpeg:
+		return "ffmpeg missing"
+	default:
+		return "failed"
+	}
+}
+
+// Transient reports whether retrying the download later may succeed
+func (k FailureKind) Transient() bool {
+	return k == FailureNetwork || k == FailureRateLimited
+}
+
+// classifyMessage finds out what kind of problem an error or warning message describes
+func classifyMessage(message string) FailureKind {
+	message = strings.ToLower(message)
+	for _, failure := range failurePatterns {
+		for _, pattern := range failure.patterns {
+			if strings.Contains(message, pattern) {
+				return failure.kind
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// classifyFailure returns why a download failed, judging by its newest classified error
+func classifyFailure(errors []YtDlpMessage) FailureKind {
+	for i := len(errors) - 1; i >= 0; i-- {
+		if errors[i].Category != FailureUnknown {
+			return errors[i].Category
+		}
+	}
+	return FailureUnknown
+}
+
+// retryDelay returns the backoff before retry number attempt (starting at 1)
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
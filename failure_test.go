@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    FailureKind
+	}{
+		{"rate limited", "Unable to download webpage: HTTP Error 429: Too Many Requests", FailureRateLimited},
+		{"network", "Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>", FailureNetwork},
+		{"server error", "unable to download video data: HTTP Error 503: Service Unavailable", FailureNetwork},
+		{"geo-blocked", "The uploader has not made this video available in your country", FailureGeoBlocked},
+		{"private", "Private video. Sign in if you've been granted access to this video", FailureUnavailable},
+		{"login", "Sign in to confirm your age. This video may be inappropriate for some users. Use --cookies-from-browser or --cookies for the authentication.", FailureLoginRequired},
+		{"ffmpeg", "You have requested merging of multiple formats but ffmpeg is not installed. Aborting due to --abort-on-error", FailureMissingFFmpeg},
+		{"unknown", "Something odd happened", FailureUnknown},
+	}
+
+	for _, test := range tests {
+		if got := classifyMessage(test.message); got != test.want {
+			t.Errorf("%s: classifyMessage() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors []YtDlpMessage
+		want   FailureKind
+	}{
+		{"newest wins", []YtDlpMessage{{Category: FailureRateLimited}, {Category: FailureUnavailable}}, FailureUnavailable},
+		{"unknown skipped", []YtDlpMessage{{Category: FailureNetwork}, {Category: FailureUnknown}}, FailureNetwork},
+		{"only unknown", []YtDlpMessage{{Category: FailureUnknown}}, FailureUnknown},
+		{"no errors", nil, FailureUnknown},
+	}
+
+	for _, test := range tests {
+		if got := classifyFailure(test.errors); got != test.want {
+			t.Errorf("%s: classifyFailure() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{10 * time.Second, 1, 10 * time.Second},
+		{10 * time.Second, 2, 20 * time.Second},
+		{10 * time.Second, 4, 80 * time.Second},
+		{10 * time.Second, 100, maxRetryDelay},
+		{time.Hour, 1, maxRetryDelay},
+	}
+
+	for _, test := range tests {
+		if got := retryDelay(test.base, test.attempt); got != test.want {
+			t.Errorf("retryDelay(%s, %d) = %s, want %s", test.base, test.attempt, got, test.want)
+		}
+	}
+}
+
+func TestQueueFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		attempts  int
+		wantState JobState
+		wantError string
+	}{
+		{"transient", "ERROR: HTTP Error 429: Too Many Requests", 1, JobRetrying, "rate limited (HTTP 429), retry 1 of 2 in 10s: HTTP Error 429: Too Many Requests"},
+		{"backoff", "ERROR: Connection reset by peer", 2, JobRetrying, "network error, retry 2 of 2 in 20s: Connection reset by peer"},
+		{"no yt-dlp error", "[download] 10.0%", 1, JobFailed, "failed: exit status 1"},
+		{"retries used up", "ERROR: Connection reset by peer", 3, JobFailed, "network error: Connection reset by peer (after 3 attempts)"},
+		{"permanent", "ERROR: [youtube] abc: Video unavailable", 1, JobFailed, "private or removed: [youtube] abc: Video unavailable"},
+	}
+
+	for _, test := range tests {
+		queue := NewQueue(1)
+		queue.Retries = 2
+		queue.RetryDelay = 10 * time.Second
+
+		job := queue.Enqueue("https://example.com", nil, nil)
+		job.State = JobRunning
+		job.Attempts = test.attempts
+		for _, line := range strings.Split(test.output, "\n") {
+			parseProgressLine(&job.Progress, line)
+		}
+
+		before := time.Now()
+		queue.fail(job, errors.New("exit status 1"))
+
+		if job.State != test.wantState {
+			t.Errorf("%s: state = %v, want %v", test.name, job.State, test.wantState)
+		}
+		if job.Progress.Error != test.wantError {
+			t.Errorf("%s: error = %q, want %q", test.name, job.Progress.Error, test.wantError)
+		}
+		if job.State == JobRetrying && job.RetryAt.Before(before.Add(retryDelay(queue.RetryDelay, job.Attempts))) {
+			t.Errorf("%s: retry is due at %s, too early", test.name, job.RetryAt)
+		}
+	}
+}
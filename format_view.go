@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	formatAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+	formatTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFDF5")).
+				Background(lipgloss.Color("#25A065")).
+				Padding(0, 1)
+)
+
+// FormatView lets the user pick a format, or a video and an audio format to merge
+type FormatView struct {
+	URL     string
+	Title   string
+	Formats []VideoFormat
+	List    list.Model
+	VideoID string // Selected video (or combined) format
+	AudioID string // Selected audio format
+}
+
+// FormatSelectedMsg is sent when a format was picked for a URL
+type FormatSelectedMsg struct {
+	URL    string
+	Format string // Value for --format, e.g. "137+140"
+}
+
+// CancelFormatMsg is sent when leaving the format picker without a choice
+type CancelFormatMsg struct{}
+
+// formatItem wraps VideoFormat to implement list.Item interface
+type formatItem struct {
+	format   VideoFormat
+	selected string // Marker shown when the format is selected
+}
+
+func (i formatItem) Title() string {
+	marker := "  "
+	if i.selected != "" {
+		marker = i.selected + " "
+	}
+
+	parts := []string{padRight(i.format.FormatID, 8), padRight(i.format.Ext, 5), padRight(formatResolution(i.format), 10)}
+	if i.format.FPS > 0 {
+		parts = append(parts, fmt.Sprintf("%gfps", i.format.FPS))
+	}
+	switch {
+	case i.format.HasVideo() && !i.format.HasAudio():
+		parts = append(parts, "video only")
+	case i.format.HasAudio() && !i.format.HasVideo():
+		parts = append(parts, "audio only")
+	}
+	return marker + strings.Join(parts, " ")
+}
+
+func (i formatItem) Description() string {
+	var parts []string
+	if i.format.HasVideo() && i.format.VCodec != "" {
+		parts = append(parts, i.format.VCodec)
+	}
+	if i.format.HasAudio() && i.format.ACodec != "" {
+		parts = append(parts, i.format.ACodec)
+	}
+	if i.format.TBR > 0 {
+		parts = append(parts, fmt.Sprintf("%.0fk", i.format.TBR))
+	}
+	if size := i.format.Size(); size > 0 {
+		parts = append(parts, formatBytes(size))
+	}
+	if i.format.FormatNote != "" {
+		parts = append(parts, i.format.FormatNote)
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (i formatItem) FilterValue() string {
+	return i.format.FormatID + " " + i.format.Ext + " " + formatResolution(i.format)
+}
+
+// NewFormatView creates a new FormatView instance
+func NewFormatView() FormatView {
+	formatsList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	formatsList.SetShowTitle(false) // Hide title
+	formatsList.SetShowHelp(false)  // We'll handle help separately
+
+	return FormatView{
+		List: formatsList,
+	}
+}
+
+// SetMetadata loads the downloadable formats of a video
+func (fv *FormatView) SetMetadata(url string, info *VideoMetadata) {
+	fv.URL = url
+	fv.Title = info.Title
+	fv.VideoID = ""
+	fv.AudioID = ""
+
+	// Skip storyboards and other formats without any media stream
+	fv.Formats = fv.Formats[:0]
+	for _, format := range info.Formats {
+		if format.HasVideo() || format.HasAudio() {
+			fv.Formats = append(fv.Formats, format)
+		}
+	}
+
+	fv.updateListItems()
+	// yt-dlp lists formats from worst to best, start at the best one
+	fv.List.Select(max(len(fv.Formats)-1, 0))
+}
+
+// updateListItems synchronizes the list items with formats and selection
+func (fv *FormatView) updateListItems() {
+	items := make([]list.Item, len(fv.Formats))
+	for i, format := range fv.Formats {
+		item := formatItem{format: format}
+		switch format.FormatID {
+		case fv.VideoID:
+			item.selected = "[V]"
+		case fv.AudioID:
+			item.selected = "[A]"
+		}
+		items[i] = item
+	}
+	fv.List.SetItems(items)
+}
+
+// Update handles input for the FormatView
+func (fv *FormatView) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := formatAppStyle.GetFrameSize()
+		header := 3 // Title and selection summary
+		fv.List.SetSize(msg.Width-h, msg.Height-v-header)
+	case tea.KeyMsg:
+		// Let the list handle filtering input on its own
+		if fv.List.FilterState() == list.Filtering {
+			var cmd tea.Cmd
+			fv.List, cmd = fv.List.Update(msg)
+			return cmd
+		}
+
+		switch msg.String() {
+		case " ":
+			fv.toggleSelected()
+		case "enter":
+			// Use the highlighted format if nothing was marked
+			if fv.VideoID == "" && fv.AudioID == "" {
+				fv.toggleSelected()
+			}
+			if selection := fv.Selection(); selection != "" {
+				url := fv.URL
+				return func() tea.Msg {
+					return FormatSelectedMsg{URL: url, Format: selection}
+				}
+			}
+		case "esc":
+			return func() tea.Msg {
+				return CancelFormatMsg{}
+			}
+		default:
+			var cmd tea.Cmd
+			fv.List, cmd = fv.List.Update(msg)
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// toggleSelected marks or unmarks the highlighted format.
+// A video-only and an audio-only format can be marked together to be merged.
+func (fv *FormatView) toggleSelected() {
+	// The list index is off while filtering, the item isn't
+	item, ok := fv.List.SelectedItem().(formatItem)
+	if !ok {
+		return
+	}
+	format := item.format
+
+	switch {
+	case format.FormatID == fv.VideoID:
+		fv.VideoID = ""
+	case format.FormatID == fv.AudioID:
+		fv.AudioID = ""
+	case format.HasVideo() && format.HasAudio():
+		// Combined formats can't be merged with anything else
+		fv.VideoID = format.FormatID
+		fv.AudioID = ""
+	case format.HasVideo():
+		fv.VideoID = format.FormatID
+	default:
+		fv.AudioID = format.FormatID
+		if fv.isCombined(fv.VideoID) {
+			fv.VideoID = ""
+		}
+	}
+
+	fv.updateListItems()
+}
+
+// isCombined reports whether formatID has both video and audio
+func (fv FormatView) isCombined(formatID string) bool {
+	for _, format := range fv.Formats {
+		if format.FormatID == formatID {
+			return format.HasVideo() && format.HasAudio()
+		}
+	}
+	return false
+}
+
+// Selection returns the --format value for the marked formats
+func (fv FormatView) Selection() string {
+	switch {
+	case fv.VideoID != "" && fv.AudioID != "":
+		return fv.VideoID + "+" + fv.AudioID
+	case fv.VideoID != "":
+		return fv.VideoID
+	default:
+		return fv.AudioID
+	}
+}
+
+// View renders the FormatView
+func (fv FormatView) View() string {
+	s := formatTitleStyle.Render("Formats: "+fv.Title) + "\n"
+
+	selection := fv.Selection()
+	if selection == "" {
+		selection = "none"
+	}
+	s += lipgloss.NewStyle().Faint(true).Render("Selected: "+selection) + "\n\n"
+
+	if len(fv.Formats) == 0 {
+		s += "No downloadable formats found"
+	} else {
+		s += fv.List.View()
+	}
+
+	return formatAppStyle.Render(s)
+}
+
+// formatResolution returns a readable resolution of a format
+func formatResolution(format VideoFormat) string {
+	if !format.HasVideo() {
+		return "audio"
+	}
+	if format.Width > 0 && format.Height > 0 {
+		return fmt.Sprintf("%dx%d", format.Width, format.Height)
+	}
+	if format.Resolution != "" {
+		return format.Resolution
+	}
+	return "?"
+}
+
+// padRight pads text with spaces up to width
+func padRight(text string, width int) string {
+	if len(text) >= width {
+		return text
+	}
+	return text + strings.Repeat(" ", width-len(text))
+}
+
+// overrideFormat replaces every --format option with the given format
+func overrideFormat(options []Option, format string) []Option {
+	var result []Option
+	for _, option := range options {
+		flagParts := option.Arguments()
+		if len(flagParts) > 0 {
+			key := strings.SplitN(flagParts[0], "=", 2)[0]
+			if key == "--format" || key == "-f" {
+				continue
+			}
+		}
+		result = append(result, option)
+	}
+
+	return append(result, Option{
+		Flag:    "--format=" + format,
+		Comment: "Picked in format list",
+		Enabled: true,
+		Source:  sourceFormatList,
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func newTestFormatView() FormatView {
+	fv := NewFormatView()
+	fv.List.SetSize(80, 40)
+	fv.SetMetadata("https://example.com", &VideoMetadata{
+		Title: "Clip",
+		Formats: []VideoFormat{
+			{FormatID: "sb0", Ext: "mhtml", VCodec: "none", ACodec: "none"},
+			{FormatID: "18", Ext: "mp4", VCodec: "avc1", ACodec: "mp4a"},
+			{FormatID: "137", Ext: "mp4", VCodec: "avc1", ACodec: "none"},
+			{FormatID: "140", Ext: "m4a", VCodec: "none", ACodec: "mp4a"},
+		},
+	})
+	return fv
+}
+
+func TestFormatViewSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		toggle []int // Positions in the format list to toggle in order
+		want   string
+	}{
+		{"nothing", nil, ""},
+		{"combined", []int{0}, "18"},
+		{"video and audio", []int{1, 2}, "137+140"},
+		{"audio replaces combined", []int{0, 2}, "140"},
+		{"combined replaces merge", []int{1, 2, 0}, "18"},
+		{"toggle off", []int{1, 1}, ""},
+	}
+
+	for _, test := range tests {
+		fv := newTestFormatView()
+		for _, index := range test.toggle {
+			fv.List.Select(index)
+			fv.toggleSelected()
+		}
+		if got := fv.Selection(); got != test.want {
+			t.Errorf("%s: Selection() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFormatViewToggleWhileFiltered(t *testing.T) {
+	fv := newTestFormatView()
+	if len(fv.Formats) != 3 {
+		t.Fatalf("kept %d formats, want 3 without the storyboard", len(fv.Formats))
+	}
+
+	// The only match sits at index 0 of the filtered list but 2 of all formats
+	fv.List.SetFilterText("m4a")
+	fv.toggleSelected()
+
+	if got := fv.Selection(); got != "140" {
+		t.Errorf("Selection() after toggling a filtered format = %q, want %q", got, "140")
+	}
+}
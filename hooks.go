@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultHookTimeout is how long a hook may run if it doesn't set its own timeout
+const defaultHookTimeout = 60 * time.Second
+
+// hookPrefix marks hook output in a job's log
+const hookPrefix = "[hook] "
+
+// hookVariable matches the variables substituted into hook commands
+var hookVariable = regexp.MustCompile(`\{(filepath|title|url|preset|id)\}`)
+
+// Hook is a command run after a successful download. Command is run by sh, or cmd.exe
+// on Windows, after its variables have been replaced with quoted values, so they must
+// not be quoted again: "notify-send {title}" rather than "notify-send '{title}'".
+type Hook struct {
+	Command string `json:"command"`           // Command line run by the shell, e.g. "notify-send {title}"
+	Timeout int    `json:"timeout,omitempty"` // Seconds before the hook is killed, 60 if unset
+}
+
+// JobHook is a hook snapshotted into a job together with where it came from
+type JobHook struct {
+	Hook
+	Preset string `json:"preset,omitempty"` // Preset defining the hook, empty for global hooks
+}
+
+// HookResult is the outcome of running a hook for one downloaded file
+type HookResult struct {
+	Command string
+	Output  []string
+	Err     error
+}
+
+// HooksDoneMsg is sent when every hook of a job has finished
+type HooksDoneMsg struct {
+	JobID   int
+	Results []HookResult
+}
+
+// TimeoutDuration returns the hook's timeout, falling back to the default
+func (h Hook) TimeoutDuration() time.Duration {
+	if h.Timeout <= 0 {
+		return defaultHookTimeout
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// expandHook replaces the variables in command with shell-quoted values
+func expandHook(command string, vars map[string]string) string {
+	return hookVariable.ReplaceAllStringFunc(command, func(match string) string {
+		return quoteHookValue(vars[strings.Trim(match, "{}")])
+	})
+}
+
+// hookVars returns the variables for every file a download produced, or for the URL alone
+// if yt-dlp didn't report any file
+func hookVars(url string, title string, presets []string, progress DownloadProgress) []map[string]string {
+	preset := strings.Join(presets, ",")
+	if len(progress.Files) == 0 {
+		return []map[string]string{{"filepath": "", "title": title, "url": url, "preset": preset, "id": ""}}
+	}
+
+	var runs []map[string]string
+	for i, path := range progress.Files {
+		vars := map[string]string{"filepath": path, "title": title, "url": url, "preset": preset, "id": ""}
+		// Playlists report a title and ID per file
+		if i < len(progress.Titles) && progress.Titles[i] != "NA" {
+			vars["title"] = progress.Titles[i]
+		}
+		if vars["title"] == "" {
+			vars["title"] = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if i < len(progress.ArchiveKeys) {
+			if _, id, ok := strings.Cut(progress.ArchiveKeys[i], " "); ok {
+				vars["id"] = id
+			}
+		}
+		runs = append(runs, vars)
+	}
+	return runs
+}
+
+// runHook runs a single hook with vars, killing it with its children once it times out
+func runHook(hook JobHook, vars map[string]string) HookResult {
+	command := expandHook(hook.Command, vars)
+	result := HookResult{Command: command}
+	logToFile("Running hook: " + command)
+
+	timeout := hook.TimeoutDuration()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := hookCommand(ctx, command)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	// Don't wait for children that keep the output open after the shell was killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		result.Output = append(result.Output, scanner.Text())
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case err != nil:
+		result.Err = err
+	}
+	return result
+}
+
+// runHooks runs every hook once per set of variables, one after another
+func runHooks(hooks []JobHook, runs []map[string]string) []HookResult {
+	var results []HookResult
+	for _, vars := range runs {
+		for _, hook := range hooks {
+			results = append(results, runHook(hook, vars))
+		}
+	}
+	return results
+}
+
+// runHooksCmd runs the hooks of a finished job in the background
+func runHooksCmd(job *Job) tea.Cmd {
+	hooks := job.Hooks
+	runs := hookVars(job.URL, job.Title, job.Presets, job.Progress)
+	jobID := job.ID
+	return func() tea.Msg {
+		return HooksDoneMsg{JobID: jobID, Results: runHooks(hooks, runs)}
+	}
+}
+
+// runHooksDirect runs hooks after a download in CLI mode and prints their output.
+// The downloaded files are read from the report yt-dlp wrote with --print-to-file.
+func runHooksDirect(url string, hooks []JobHook, presets []string, reportPath string) {
+	var progress DownloadProgress
+	if data, err := os.ReadFile(reportPath); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			parseProgressLine(&progress, line)
+		}
+	}
+
+	for _, result := range runHooks(hooks, hookVars(url, "", presets, progress)) {
+		for _, line := range result.hookLog() {
+			fmt.Println(line)
+		}
+	}
+}
+
+// hookLog returns the log lines of a hook result
+func (r HookResult) hookLog() []string {
+	lines := []string{hookPrefix + "$ " + r.Command}
+	for _, line := range r.Output {
+		lines = append(lines, hookPrefix+line)
+	}
+	if r.Err != nil {
+		lines = append(lines, hookPrefix+"failed: "+r.Err.Error())
+	}
+	return lines
+}
+
+// ApplyHookResults adds the output of a job's hooks to its log and records failures.
+// A failing hook doesn't fail the download.
+func (q *Queue) ApplyHookResults(msg HooksDoneMsg) *Job {
+	job := q.Job(msg.JobID)
+	if job == nil {
+		return nil
+	}
+
+	job.HooksRunning = false
+	for _, result := range msg.Results {
+		job.Progress.Output = append(job.Progress.Output, result.hookLog()...)
+		if result.Err != nil {
+			job.HookErrors = append(job.HookErrors, result.Command+": "+result.Err.Error())
+			logToFile(fmt.Sprintf("Hook of job %d failed: %s", job.ID, result.Err.Error()))
+		}
+	}
+	if len(job.Progress.Output) > maxOutputLines {
+		job.Progress.Output = job.Progress.Output[len(job.Progress.Output)-maxOutputLines:]
+	}
+	return job
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandHook(t *testing.T) {
+	vars := map[string]string{"filepath": "/tmp/a b.mp4", "title": "It's 100% $HOME", "url": "https://example.com", "preset": "Best", "id": ""}
+
+	got := expandHook("mv {filepath} /media && notify {title} {id} {unknown} {url}", vars)
+	want := "mv " + quoteHookValue("/tmp/a b.mp4") + " /media && notify " + quoteHookValue("It's 100% $HOME") + " " +
+		quoteHookValue("") + " {unknown} " + quoteHookValue("https://example.com")
+	if got != want {
+		t.Errorf("expandHook() = %s, want %s", got, want)
+	}
+}
+
+func TestRunHookPassesValuesVerbatim(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("echo keeps the quotes on Windows")
+	}
+	title := `It's "100%" $HOME; rm -rf *`
+	result := runHook(JobHook{Hook: Hook{Command: "printf '%s\\n' {title}"}}, map[string]string{"title": title})
+	if result.Err != nil {
+		t.Fatalf("runHook() error = %v", result.Err)
+	}
+	if !reflect.DeepEqual(result.Output, []string{title}) {
+		t.Errorf("runHook() output = %q, want %q", result.Output, title)
+	}
+}
+
+func TestRunHookTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	result := runHook(JobHook{Hook: Hook{Command: "sleep 5", Timeout: 1}}, nil)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Errorf("runHook() error = %v, want a timeout", result.Err)
+	}
+}
+
+func TestHookTimeoutDuration(t *testing.T) {
+	if got := (Hook{}).TimeoutDuration(); got != defaultHookTimeout {
+		t.Errorf("TimeoutDuration() without timeout = %s, want %s", got, defaultHookTimeout)
+	}
+	if got := (Hook{Timeout: 5}).TimeoutDuration(); got != 5*time.Second {
+		t.Errorf("TimeoutDuration() = %s, want 5s", got)
+	}
+}
+
+func TestHookVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		progress DownloadProgress
+		want     []map[string]string
+	}{
+		{
+			name:  "no files reported",
+			title: "Video",
+			want:  []map[string]string{{"filepath": "", "title": "Video", "url": "https://example.com", "preset": "Best,Audio", "id": ""}},
+		},
+		{
+			name: "playlist",
+			progress: DownloadProgress{
+				Files:       []string{"/dl/One.mp4", "/dl/Two.mp4"},
+				Titles:      []string{"First", "NA"},
+				ArchiveKeys: []string{"Youtube abc", "Youtube def"},
+			},
+			want: []map[string]string{
+				{"filepath": "/dl/One.mp4", "title": "First", "url": "https://example.com", "preset": "Best,Audio", "id": "abc"},
+				{"filepath": "/dl/Two.mp4", "title": "Two", "url": "https://example.com", "preset": "Best,Audio", "id": "def"},
+			},
+		},
+		{
+			name:     "title known when queueing",
+			title:    "Queued title",
+			progress: DownloadProgress{Files: []string{"/dl/file.mkv"}},
+			want:     []map[string]string{{"filepath": "/dl/file.mkv", "title": "Queued title", "url": "https://example.com", "preset": "Best,Audio", "id": ""}},
+		},
+	}
+
+	for _, test := range tests {
+		got := hookVars("https://example.com", test.title, []string{"Best", "Audio"}, test.progress)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: hookVars() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var importAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+// ImportView asks for a file of URLs to load into the queue
+type ImportView struct {
+	PathInput textinput.Model
+	Error     string
+	Warning   string // Invalid lines found in the file, shown before importing the rest
+}
+
+// CancelImportMsg is sent when leaving the import view
+type CancelImportMsg struct{}
+
+// NewImportView creates a new ImportView instance
+func NewImportView() ImportView {
+	pathInput := textinput.New()
+	pathInput.Placeholder = "~/urls.txt"
+	pathInput.CharLimit = 1024
+	pathInput.Width = 80
+
+	return ImportView{
+		PathInput: pathInput,
+	}
+}
+
+// Reset clears the path and focuses the input
+func (iv *ImportView) Reset() {
+	iv.PathInput.Reset()
+	iv.PathInput.Focus()
+	iv.Error = ""
+	iv.Warning = ""
+}
+
+// Update handles input for the ImportView
+func (iv *ImportView) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			path := iv.PathInput.Value()
+			if path == "" {
+				return nil
+			}
+
+			urls, rejected, err := loadURLList(path)
+			if err != nil {
+				iv.Error = err.Error()
+				return nil
+			}
+			if len(urls) == 0 {
+				iv.Error = "No valid URL found in " + path
+				return nil
+			}
+
+			// Point out invalid lines first, importing again keeps the valid URLs
+			if len(rejected) > 0 && iv.Warning == "" {
+				iv.Warning = fmt.Sprintf("Invalid URLs on %s • Enter again to import the other %d", lineList(rejected), len(urls))
+				return nil
+			}
+
+			return func() tea.Msg {
+				return EnqueueURLsMsg{URLs: urls}
+			}
+		case "esc":
+			return func() tea.Msg {
+				return CancelImportMsg{}
+			}
+		default:
+			iv.PathInput, cmd = iv.PathInput.Update(msg)
+			iv.Error = ""
+			iv.Warning = ""
+		}
+	}
+
+	return cmd
+}
+
+// View renders the ImportView
+func (iv ImportView) View() string {
+	content := fmt.Sprintf("Import URLs from file:\n\n%s\n%s\n\n", focusedLabelStyle.Render("File:"), iv.PathInput.View())
+	content += lipgloss.NewStyle().Faint(true).Render("One URL per line, lines starting with #, ; or ] are skipped") + "\n"
+
+	if iv.Error != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ "+iv.Error) + "\n"
+	} else if iv.Warning != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("⚠ "+iv.Warning) + "\n"
+	}
+
+	return importAppStyle.Render(content)
+}
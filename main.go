@@ -2,12 +2,15 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -43,6 +46,38 @@ func initialModel() Model {
 			key.WithKeys("enter"),
 			key.WithHelp("Enter", "download"),
 		),
+		Formats: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("Ctrl+F", "pick format"),
+		),
+		Import: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("Ctrl+O", "import URLs from file"),
+		),
+		Paste: key.NewBinding(
+			key.WithKeys("ctrl+v"),
+			key.WithHelp("Ctrl+V", "paste text with links"),
+		),
+		Command: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("Ctrl+P", "show command"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "cancel download"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause download"),
+		),
+		Resume: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "resume download"),
+		),
+		Schedule: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "schedule download"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "toggle help"),
@@ -54,6 +89,24 @@ func initialModel() Model {
 		// Removed tab switching keys
 	}
 
+	queue := NewQueue(appSettings.Workers)
+	queue.Retries = appSettings.Retries
+	queue.RetryDelay = time.Duration(appSettings.RetryDelay) * time.Second
+	queue.RateLimit, _ = parseRate(appSettings.RateLimit)
+	queue.DomainLimits = appSettings.DomainLimits
+
+	// Jobs left unfinished by the last session are only saved over once the user decided about them
+	var savedJobs []SavedJob
+	queuePath, err := getQueueFilePath()
+	if err == nil {
+		savedJobs, err = LoadQueueState(queuePath)
+	}
+	if err != nil {
+		logToFile("Failed to load saved queue: " + err.Error())
+	} else if len(savedJobs) == 0 {
+		queue.StatePath = queuePath
+	}
+
 	// Create basic model structure
 	model := Model{
 		Tab:           URLTab, // start with URL tab
@@ -61,7 +114,16 @@ func initialModel() Model {
 		PresetsView:   NewPresetsView(),
 		PresetView:    NewPresetView(),
 		AddOptionView: NewAddOptionView(),
+		DownloadsView: NewDownloadsView(queue, keys),
+		FormatView:    NewFormatView(),
+		PlaylistView:  NewPlaylistView(),
+		ImportView:    NewImportView(),
+		PasteView:     NewPasteView(),
+		ResumeView:    NewResumeView(savedJobs),
+		ConflictView:  NewConflictView(),
 		CurrentView:   MainView,
+		Queue:         queue,
+		ProgressBar:   progress.New(progress.WithDefaultGradient()),
 		Width:         150, // Very wide default
 		Height:        40,  // Tall default
 		Keys:          keys,
@@ -72,12 +134,18 @@ func initialModel() Model {
 	// Set initial focus
 	model.updateFocus()
 
+	// Ask whether to resume the saved jobs first
+	if len(savedJobs) > 0 {
+		model.CurrentView = ResumePromptView
+		model.URLView.Blur()
+	}
+
 	return model
 }
 
 // Init initializes the application
 func (m Model) Init() tea.Cmd {
-	return tea.EnterAltScreen
+	return tea.Batch(tea.EnterAltScreen, waitForDownloadMsg(m.Queue.events), checkYtDlpCmd())
 }
 
 // Update handles all input and updates the model
@@ -92,6 +160,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Height = msg.Height
 		// Update help width
 		m.Help.Width = msg.Width
+		// Keep the progress bar within the window
+		m.ProgressBar.Width = min(msg.Width-4, 80)
 		// Update URLView flexbox size
 		m.URLView.Update(msg)
 		// Update PresetsView list size
@@ -100,6 +170,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.PresetView.Update(msg)
 		// Update AddOptionView flexbox size
 		m.AddOptionView.Update(msg)
+		// Update DownloadsView height
+		m.DownloadsView.Update(msg)
+		// Update FormatView list size
+		m.FormatView.Update(msg)
+		// Update PlaylistView list size
+		m.PlaylistView.Update(msg)
+		// Update PasteView text area size
+		m.PasteView.Update(msg)
 		return m, nil
 
 	case tea.KeyMsg:
@@ -110,10 +188,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.ShowHelp = m.Help.ShowAll
 		case key.Matches(msg, m.Keys.Download):
 			// Start download only on URL tab if URL is provided
-			if m.Tab == URLTab && m.URLView.CurrentURL != "" {
-				// Get merged options (presets + CLI args)
-				mergedOptions := m.PresetsView.GetMergedOptions(cliArgs)
-				return m, ExecuteYtDlpCmd(m.URLView.CurrentURL, mergedOptions)
+			if m.Tab == URLTab && m.CurrentView == MainView && m.URLView.CurrentURL != "" {
+				m.requestDownload()
+				return m, nil
 			}
 			// Don't handle Enter for other tabs - let them handle it themselves
 			if m.Tab != URLTab {
@@ -124,11 +201,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle input based on current tab
 		switch m.Tab {
 		case URLTab:
+			// Handle format picker input
+			if m.CurrentView == FormatPickerView {
+				cmd = m.FormatView.Update(msg)
+				break
+			}
+			// Handle playlist entries input
+			if m.CurrentView == PlaylistPickerView {
+				cmd = m.PlaylistView.Update(msg)
+				break
+			}
+			// Handle file import input
+			if m.CurrentView == ImportFileView {
+				cmd = m.ImportView.Update(msg)
+				break
+			}
+			// Handle paste mode input
+			if m.CurrentView == PasteModeView {
+				cmd = m.PasteView.Update(msg)
+				break
+			}
+			// Handle the resume prompt
+			if m.CurrentView == ResumePromptView {
+				cmd = m.ResumeView.Update(msg)
+				break
+			}
+
 			// Handle URL view input
 			if msg.String() == "esc" {
 				return m, tea.Quit
 			}
+			if key.Matches(msg, m.Keys.Formats) {
+				// Open the format list once metadata has been fetched
+				if info := m.URLView.MetadataFor(m.URLView.CurrentURL); info != nil && len(info.Formats) > 0 {
+					m.FormatView.SetMetadata(m.URLView.CurrentURL, info)
+					m.CurrentView = FormatPickerView
+				}
+				return m, nil
+			}
+			if key.Matches(msg, m.Keys.Command) {
+				m.ShowCommand = !m.ShowCommand
+				return m, nil
+			}
+			if key.Matches(msg, m.Keys.Import) {
+				m.ImportView.Reset()
+				m.CurrentView = ImportFileView
+				m.URLView.Blur()
+				return m, nil
+			}
+			// Pasting several lines or links opens paste mode with the pasted text
+			pastedLinks := msg.Paste && m.URLView.FocusState == FocusInput && containsMultipleURLs(string(msg.Runes))
+			if key.Matches(msg, m.Keys.Paste) || pastedLinks {
+				text := ""
+				if pastedLinks {
+					text = string(msg.Runes)
+				}
+				m.PasteView.Reset(text, m.PresetsView.ActivePresetNames())
+				m.CurrentView = PasteModeView
+				m.URLView.Blur()
+				return m, nil
+			}
 			cmd = m.URLView.Update(msg)
+			m.checkDuplicate()
 
 		case PresetsTab:
 			// Handle presets view input
@@ -153,13 +287,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Create new preset
 					m.CurrentView = EditPresetView
 					m.PresetView.SetNewPresetMode()
+				case "c", "C":
+					// Show which presets override each other
+					m.ConflictView.SetPresets(m.PresetsView)
+					m.CurrentView = ConflictReportView
 				default:
 					_ = m.PresetsView.Update(msg)
 				}
 			} else if m.CurrentView == EditPresetView {
 				// Handle preset editing
-				switch msg.String() {
-				case "esc":
+				switch {
+				case key.Matches(msg, m.Keys.Command):
+					m.ShowCommand = !m.ShowCommand
+				case msg.String() == "esc" && !m.PresetView.EditingOutput:
 					// Go back to main view
 					m.CurrentView = MainView
 				default:
@@ -171,6 +311,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						cmd = updateCmd
 					}
 				}
+			} else if m.CurrentView == ConflictReportView {
+				// Go back to the presets list
+				if msg.String() == "esc" {
+					m.CurrentView = MainView
+				}
 			} else if m.CurrentView == AddOptionViewMode {
 				// Handle add option view
 				switch msg.String() {
@@ -186,54 +331,151 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			// Auto-save config after any changes
 			AutoSaveConfig(&m.URLView, &m.PresetsView)
-		}
 
-	// Handle yt-dlp process finished
-	case ytDlpFinishedMsg:
-		if msg.err != nil {
-			logToFile("yt-dlp finished with error: " + msg.err.Error())
-		} else {
-			logToFile("yt-dlp finished successfully")
-
-			// Try to find the downloaded file
-			filename, err := findDownloadedFile(msg.downloadStartTime)
-			if err != nil {
-				logToFile("Could not find downloaded file: " + err.Error())
-				// Use fallback name
-				filename = generateVideoName(msg.url)
-			} else {
-				logToFile("Found downloaded file: " + filename)
+		case DownloadsTab:
+			// Handle downloads dashboard input
+			if msg.String() == "esc" && !m.DownloadsView.Scheduling {
+				// Go back to URL tab
+				m.Tab = URLTab
+				m.updateFocus()
+				return m, nil
 			}
-
-			// Add to history with actual filename
-			m.URLView.AddToHistory(msg.url, filename)
-
-			// Auto-save complete config
-			AutoSaveConfig(&m.URLView, &m.PresetsView)
+			cmd = tea.Batch(m.DownloadsView.Update(msg), m.tickQueue())
 		}
-		// Continue running the app after yt-dlp finishes
-		return m, nil
 
-	// Handle download messages (keeping for compatibility)
+	// Handle download messages
 	case DownloadMsg:
 		// If this is a download request from button (not actual progress)
-		if !msg.Done && msg.Progress.State == DownloadIdle {
-			// Start download if URL is valid
+		if msg.JobID == 0 {
 			if m.URLView.CurrentURL != "" {
-				mergedOptions := m.PresetsView.GetMergedOptions(cliArgs)
-				return m, ExecuteYtDlpCmd(m.URLView.CurrentURL, mergedOptions)
+				m.requestDownload()
 			}
+			return m, nil
 		}
 
-		m.Download = msg.Progress
-		if msg.Done {
-			if msg.Error != nil {
-				m.Download.State = DownloadError
-				m.Download.Error = msg.Error.Error()
-			} else if m.Download.State != DownloadError {
-				m.Download.State = DownloadCompleted
+		cmds := []tea.Cmd{waitForDownloadMsg(m.Queue.events)}
+		job := m.Queue.Handle(msg)
+		if job != nil && msg.Done {
+			m.finishJob(job)
+			// Hooks run in the background, a failing hook doesn't fail the download
+			if job.State == JobDone && len(job.Hooks) > 0 {
+				job.HooksRunning = true
+				cmds = append(cmds, runHooksCmd(job))
 			}
+			// A worker is free, start the next queued job
+			m.Queue.Schedule()
+			// Count down until failed jobs are retried
+			cmds = append(cmds, m.tickQueue())
+		}
+		// Keep listening for further progress
+		return m, tea.Batch(cmds...)
+
+	// Handle the output of post-download hooks
+	case HooksDoneMsg:
+		m.Queue.ApplyHookResults(msg)
+		return m, nil
+
+	// Start jobs that became ready and keep ticking while more are waiting
+	case queueTickMsg:
+		m.QueueTicking = false
+		m.Queue.Schedule()
+		return m, m.tickQueue()
+
+	// Handle the result of the startup yt-dlp check
+	case YtDlpVersionMsg:
+		m.YtDlpVersion = msg.Version
+		m.YtDlpWarning = msg.Warning
+		if msg.Version == "" {
+			return m, nil
+		}
+		return m, loadOptionCatalogCmd(msg.Version)
+
+	// Enable flag completion once the yt-dlp options are known
+	case OptionCatalogMsg:
+		if msg.Catalog != nil {
+			m.AddOptionView.SetCatalog(msg.Catalog)
+			m.PresetView.Catalog = msg.Catalog
+			m.PresetsView.Catalog = msg.Catalog
+			m.PresetView.updateOptionsList()
+		}
+		return m, nil
+
+	// Handle metadata prefetching for the URL view
+	case metadataDebounceMsg:
+		// Fetch with the active presets' cookies and proxy, like the download itself
+		msg.Args = metadataArgs(m.PresetsView.GetActiveOptions())
+		return m, m.URLView.Update(msg)
+	case MetadataMsg:
+		cmd := m.URLView.Update(msg)
+		m.checkDuplicate()
+		return m, cmd
+
+	// Handle format picked in the format list
+	case FormatSelectedMsg:
+		m.URLView.PickedFormats[msg.URL] = msg.Format
+		m.CurrentView = MainView
+		m.updateFocus()
+		return m, nil
+
+	// Handle leaving the format list
+	case CancelFormatMsg:
+		m.CurrentView = MainView
+		m.updateFocus()
+		return m, nil
+
+	// Handle queueing the selected playlist entries
+	case EnqueueEntriesMsg:
+		m.enqueueEntries(msg.Entries)
+		m.CurrentView = MainView
+		m.updateFocus()
+		return m, nil
+
+	// Handle leaving the playlist entries
+	case CancelPlaylistMsg:
+		m.CurrentView = MainView
+		m.updateFocus()
+		return m, nil
+
+	// Handle URLs imported from a file or found in pasted text
+	case EnqueueURLsMsg:
+		for _, url := range msg.URLs {
+			m.enqueue(url, m.jobOptions(url))
+		}
+		m.Queue.Schedule()
+		// Show the queue with everything that was added
+		m.CurrentView = MainView
+		m.Tab = DownloadsTab
+		return m, nil
+
+	// Handle the decision about the jobs left unfinished by the last session
+	case ResumeQueueMsg:
+		path, err := getQueueFilePath()
+		if err != nil {
+			logToFile("Failed to locate saved queue: " + err.Error())
+		}
+		m.Queue.StatePath = path
+		m.CurrentView = MainView
+		if !msg.Resume {
+			// Forget the saved jobs
+			m.Queue.Save()
+			m.updateFocus()
+			return m, nil
 		}
+		m.Queue.Restore(msg.Jobs)
+		m.Queue.Schedule()
+		m.Tab = DownloadsTab
+		return m, m.tickQueue()
+
+	// Handle leaving paste mode
+	case CancelPasteMsg:
+		m.CurrentView = MainView
+		m.updateFocus()
+		return m, nil
+
+	// Handle leaving the file import
+	case CancelImportMsg:
+		m.CurrentView = MainView
+		m.updateFocus()
 		return m, nil
 
 	// Handle tab switching from buttons
@@ -290,6 +532,204 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// requestDownload queues the current URL, or lets the user pick entries if it's a playlist
+func (m *Model) requestDownload() {
+	// Downloading a video again has to be confirmed with a second press
+	if m.URLView.Duplicate != nil && !m.URLView.DuplicateConfirmed {
+		m.URLView.DuplicateConfirmed = true
+		return
+	}
+
+	if info := m.URLView.MetadataFor(m.URLView.CurrentURL); info != nil && info.IsPlaylist() {
+		m.PlaylistView.SetMetadata(m.URLView.CurrentURL, info)
+		m.CurrentView = PlaylistPickerView
+		m.URLView.Blur()
+		return
+	}
+
+	m.enqueueCurrentURL()
+}
+
+// enqueueCurrentURL queues the current URL with a snapshot of the merged options
+func (m *Model) enqueueCurrentURL() {
+	url := m.URLView.CurrentURL
+	m.enqueue(url, m.jobOptions(url))
+	m.Queue.Schedule()
+	m.clearURL()
+}
+
+// enqueue queues url with the active presets, recording it in their download archive
+func (m *Model) enqueue(url string, options []Option) *Job {
+	job := m.Queue.Enqueue(url, options, m.PresetsView.ActivePresetNames())
+	job.Archive = m.PresetsView.ArchivePath()
+	job.Hooks = m.PresetsView.ActiveHooks()
+	return job
+}
+
+// enqueueEntries queues every playlist entry as its own job
+func (m *Model) enqueueEntries(entries []PlaylistEntry) {
+	options := m.jobOptions(m.URLView.CurrentURL)
+	for _, entry := range entries {
+		job := m.enqueue(entry.EntryURL(), options)
+		job.Title = entry.Title
+	}
+	m.Queue.Schedule()
+	m.clearURL()
+}
+
+// jobOptions returns the options a new job for url should be started with
+func (m *Model) jobOptions(url string) []Option {
+	mergedOptions := m.previewOptions(url)
+	// The picked format is only used for this job
+	delete(m.URLView.PickedFormats, url)
+	return mergedOptions
+}
+
+// previewOptions returns the options a new job for url would be started with, without using them up
+func (m Model) previewOptions(url string) []Option {
+	// Get merged options (presets + CLI args)
+	cliOptions, _ := parseCLIArgs(cliArgs, m.PresetsView.Catalog)
+	mergedOptions := m.PresetsView.GetMergedOptions(cliOptions)
+
+	// A format picked in the format list wins over presets for this job only
+	if format, ok := m.URLView.PickedFormats[url]; ok {
+		mergedOptions = overrideFormat(mergedOptions, format)
+	}
+
+	return mergedOptions
+}
+
+// renderPresetCommand renders the command preview below the preset editor
+func (m Model) renderPresetCommand() string {
+	s := m.commandPreview("", m.Width-4)
+	if !m.PresetView.Preset.Active {
+		s += "\n" + lipgloss.NewStyle().Faint(true).Render(m.PresetView.Preset.Name+" is inactive, its options aren't used")
+	}
+	return lipgloss.NewStyle().Padding(0, 2).Render(s)
+}
+
+// commandPreview renders the command a download of url would run if it started now, wrapped to width
+func (m Model) commandPreview(url string, width int) string {
+	if url == "" {
+		url = "URL"
+	}
+	options := applyRateLimit(m.previewOptions(url), m.Queue.rateShare(m.Queue.Running()+1))
+	return renderCommandPreview(url, options, width)
+}
+
+// clearURL clears the input so the next URL can be pasted right away
+func (m *Model) clearURL() {
+	m.URLView.URLInput.Reset()
+	m.URLView.CurrentURL = ""
+	m.URLView.IsValidURL = false
+	m.URLView.IsInHistory = false
+	m.checkDuplicate()
+}
+
+// tickQueue starts waking the queue up every second if jobs are waiting and it isn't already
+func (m *Model) tickQueue() tea.Cmd {
+	if m.QueueTicking || !m.Queue.NeedsTick() {
+		return nil
+	}
+	m.QueueTicking = true
+	return queueTickCmd()
+}
+
+// checkDuplicate looks the current URL up in the download archive once its metadata is known
+func (m *Model) checkDuplicate() {
+	url := m.URLView.CurrentURL
+	if url != m.URLView.DuplicateURL {
+		m.URLView.DuplicateURL = url
+		m.URLView.DuplicateConfirmed = false
+	}
+	m.URLView.Duplicate = nil
+
+	info := m.URLView.MetadataFor(url)
+	if info == nil || info.IsPlaylist() {
+		return
+	}
+	path := m.PresetsView.ArchivePath()
+	if path == "" {
+		return
+	}
+	archive, err := OpenArchive(path)
+	if err != nil {
+		logToFile("Failed to open download archive: " + err.Error())
+		return
+	}
+	if record, ok := archive.Lookup(archiveKey(info.ExtractorKey, info.ID)); ok {
+		m.URLView.Duplicate = &record
+	}
+}
+
+// recordInArchive adds the files of a finished job to its download archive
+func (m *Model) recordInArchive(job *Job, title string) {
+	if job.Archive == "" {
+		return
+	}
+	archive, err := OpenArchive(job.Archive)
+	if err != nil {
+		logToFile("Failed to open download archive: " + err.Error())
+		return
+	}
+
+	keys := job.Progress.ArchiveKeys
+	if len(keys) == 0 {
+		// Fall back to the prefetched metadata if yt-dlp didn't print the key
+		if info := m.URLView.MetadataFor(job.URL); info != nil && !info.IsPlaylist() {
+			keys = []string{archiveKey(info.ExtractorKey, info.ID)}
+		}
+	}
+
+	if err := archive.RecordFiles(job.URL, title, keys, job.Progress.Files); err != nil {
+		logToFile("Failed to update download archive: " + err.Error())
+	}
+}
+
+// finishJob records the result of a job that has just finished
+func (m *Model) finishJob(job *Job) {
+	switch job.State {
+	case JobRetrying:
+		logToFile(fmt.Sprintf("Job %d will be retried: %s", job.ID, job.Progress.Error))
+		return
+	case JobFailed:
+		logToFile(fmt.Sprintf("Job %d finished with error: %s", job.ID, job.Progress.Error))
+	case JobDone:
+		logToFile(fmt.Sprintf("Job %d finished successfully", job.ID))
+	default:
+		return
+	}
+
+	// Prefer the video title, then the final path reported by yt-dlp,
+	// and fall back to a name based on the URL
+	name := generateVideoName(job.URL)
+	if job.Title != "" {
+		name = job.Title
+	} else if info := m.URLView.MetadataFor(job.URL); info != nil && info.Title != "" {
+		name = info.Title
+	} else if files := job.Progress.Files; len(files) > 0 {
+		name = filepath.Base(files[0])
+		if len(files) > 1 {
+			name += fmt.Sprintf(" (+%d more)", len(files)-1)
+		}
+	} else if job.State == JobDone {
+		logToFile("yt-dlp did not report any downloaded file")
+	}
+
+	// Keep the reason of permanent failures visible when browsing history
+	if job.State == JobFailed {
+		name = "✗ " + job.Failure.String() + ": " + name
+	} else {
+		m.recordInArchive(job, name)
+	}
+
+	// Add to history with actual filename
+	m.URLView.AddToHistory(job.URL, name)
+
+	// Auto-save complete config
+	AutoSaveConfig(&m.URLView, &m.PresetsView)
+}
+
 // updateFocus sets focus based on current tab
 func (m *Model) updateFocus() {
 	// Blur all first
@@ -318,37 +758,59 @@ func (m *Model) resetPresetsView() {
 	m.PresetView.OptionsList.Select(0)
 }
 
-// renderDownloadProgress renders the download progress information
+// renderDownloadProgress renders progress of running jobs and a queue summary
 func (m Model) renderDownloadProgress() string {
-	switch m.Download.State {
+	var lines []string
+	for _, job := range m.Queue.Jobs {
+		if job.State == JobRunning {
+			lines = append(lines, m.renderJobProgress(job.Progress))
+		}
+	}
+
+	summary := fmt.Sprintf("Queue: %d running • %d queued • %d done • %d failed",
+		m.Queue.Count(JobRunning), m.Queue.Count(JobQueued), m.Queue.Count(JobDone), m.Queue.Count(JobFailed))
+	if retrying := m.Queue.Count(JobRetrying); retrying > 0 {
+		summary += fmt.Sprintf(" • %d retrying", retrying)
+	}
+	if m.Queue.RateLimit > 0 {
+		summary += " • limit " + formatRate(m.Queue.RateLimit) + "/s"
+	}
+	lines = append(lines, lipgloss.NewStyle().Faint(true).Render(summary))
+
+	return strings.Join(lines, "\n")
+}
+
+// renderJobProgress renders the progress information of a single download
+func (m Model) renderJobProgress(download DownloadProgress) string {
+	switch download.State {
 	case DownloadRunning:
 		style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
-		progress := "Downloading..." + "\n"
-		if m.Download.Percentage != "" {
-			progress = fmt.Sprintf("Downloading: %s", m.Download.Percentage)
+		progress := "Downloading..."
+		if download.Percentage != "" {
+			progress = fmt.Sprintf("Downloading: %s", download.Percentage)
 		}
-		if m.Download.Speed != "" {
-			progress += fmt.Sprintf(" at %s", m.Download.Speed)
+		if download.Speed != "" {
+			progress += fmt.Sprintf(" at %s", download.Speed)
 		}
-		if m.Download.ETA != "" {
-			progress += fmt.Sprintf(" (ETA: %s)", m.Download.ETA)
+		if download.ETA != "" {
+			progress += fmt.Sprintf(" (ETA: %s)", download.ETA)
 		}
-		if m.Download.Filename != "" {
-			progress += fmt.Sprintf("\nFile: %s", m.Download.Filename)
+		if download.Filename != "" {
+			progress += fmt.Sprintf("\nFile: %s", download.Filename)
 		}
-		return style.Render(progress)
+		return m.ProgressBar.ViewAs(download.Percent()) + "\n" + style.Render(progress)
 
 	case DownloadCompleted:
 		style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
 		result := "✅ Download completed!"
-		if m.Download.Filename != "" {
-			result += fmt.Sprintf("\nFile: %s", m.Download.Filename)
+		if download.Filename != "" {
+			result += fmt.Sprintf("\nFile: %s", download.Filename)
 		}
 		return style.Render(result)
 
 	case DownloadError:
 		style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
-		return style.Render(fmt.Sprintf("❌ Download error: %s", m.Download.Error))
+		return style.Render(fmt.Sprintf("❌ Download error: %s", download.Error))
 
 	default:
 		return ""
@@ -368,15 +830,41 @@ func (m Model) View() string {
 	var tabContent string
 	switch m.Tab {
 	case URLTab:
-		tabContent = m.URLView.View()
+		if m.CurrentView == FormatPickerView {
+			tabContent = m.FormatView.View()
+		} else if m.CurrentView == PlaylistPickerView {
+			tabContent = m.PlaylistView.View()
+		} else if m.CurrentView == ImportFileView {
+			tabContent = m.ImportView.View()
+		} else if m.CurrentView == PasteModeView {
+			tabContent = m.PasteView.View()
+		} else if m.CurrentView == ResumePromptView {
+			tabContent = m.ResumeView.View()
+		} else {
+			urlView := m.URLView
+			if m.ShowCommand {
+				urlView.CommandPreview = m.commandPreview(urlView.CurrentURL, m.Width)
+			}
+			tabContent = urlView.View()
+		}
 	case PresetsTab:
 		if m.CurrentView == MainView {
 			tabContent = m.PresetsView.View()
 		} else if m.CurrentView == EditPresetView {
-			tabContent = m.PresetView.View()
+			// Preview output filenames with the video typed on the URL screen
+			presetView := m.PresetView
+			presetView.Sample = m.URLView.MetadataFor(m.URLView.CurrentURL)
+			tabContent = presetView.View()
+			if m.ShowCommand && m.PresetView.Preset != nil {
+				tabContent += "\n" + m.renderPresetCommand()
+			}
 		} else if m.CurrentView == AddOptionViewMode {
 			tabContent = m.AddOptionView.View()
+		} else if m.CurrentView == ConflictReportView {
+			tabContent = m.ConflictView.View()
 		}
+	case DownloadsTab:
+		tabContent = m.DownloadsView.View()
 	}
 
 	// Simple content rendering
@@ -384,20 +872,47 @@ func (m Model) View() string {
 
 	// Add help first
 	if m.Tab == URLTab {
-		// Show URL help always with Esc: quit
-		s += "\n" + getURLHelpText(m.ShowHelp)
+		if m.CurrentView == FormatPickerView {
+			s += "\n" + getFormatHelpText(m.ShowHelp)
+		} else if m.CurrentView == PlaylistPickerView {
+			s += "\n" + getPlaylistHelpText(m.ShowHelp)
+		} else if m.CurrentView == ImportFileView {
+			s += "\n" + lipgloss.NewStyle().Faint(true).Render("Enter: import • Esc: cancel")
+		} else if m.CurrentView == PasteModeView {
+			s += "\n" + lipgloss.NewStyle().Faint(true).Render("Ctrl+S: queue all • Esc: cancel")
+		} else if m.CurrentView == ResumePromptView {
+			s += "\n" + lipgloss.NewStyle().Faint(true).Render("Enter/Y: resume • N: discard")
+		} else {
+			// Show URL help always with Esc: quit
+			s += "\n" + getURLHelpText(m.ShowHelp)
+		}
 	} else if m.Tab == PresetsTab {
 		if m.CurrentView == MainView {
 			s += "\n" + getPresetsHelpText(m.ShowHelp)
 		} else if m.CurrentView == AddOptionViewMode {
 			s += "\n" + getAddOptionHelpText(m.AddOptionView.InputFocus, m.ShowHelp)
+		} else if m.CurrentView == ConflictReportView {
+			s += "\n" + lipgloss.NewStyle().Faint(true).Render("Esc: back")
+		} else if m.PresetView.EditingOutput {
+			s += "\n" + lipgloss.NewStyle().Faint(true).Render("Enter: save • Tab/↑/↓: next field • Esc: cancel")
 		} else {
 			s += "\n" + getPresetHelpText(m.PresetView.InputFocus, m.ShowHelp)
 		}
+	} else if m.Tab == DownloadsTab {
+		s += "\n" + getDownloadsHelpText(m.ShowHelp)
 	}
 
-	// Add download progress at the very bottom if downloading
-	if m.Download.State != DownloadIdle {
+	// Warn about a missing or outdated yt-dlp
+	if m.YtDlpWarning != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("⚠ "+m.YtDlpWarning)
+	}
+	// Warn about settings that were ignored
+	if m.SettingsError != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("⚠ "+m.SettingsError)
+	}
+
+	// Add download progress at the very bottom once something was queued
+	if len(m.Queue.Jobs) > 0 && m.Tab != DownloadsTab {
 		s += "\n" + m.renderDownloadProgress()
 	}
 
@@ -408,7 +923,7 @@ func (m Model) View() string {
 func getPresetsHelpText(showHelp bool) string {
 	help := lipgloss.NewStyle().Faint(true)
 	if showHelp {
-		return help.Render("N: new preset • Enter: edit • Space: toggle • D: delete • R: reset all • Esc: back • ?: hide help")
+		return help.Render("N: new preset • Enter: edit • Space: toggle • K/J: move • C: conflicts • D: delete • R: reset all • Esc: back • ?: hide help")
 	}
 	return help.Render("?: help")
 }
@@ -423,9 +938,9 @@ func getPresetHelpText(inputFocus int, showHelp bool) string {
 
 	switch inputFocus {
 	case 0, 1: // Input fields
-		return help.Render("Enter: add option • Tab/↓: next field • Esc: back • ?: hide help")
+		return help.Render("Enter: add option • Tab/↓: next field • Ctrl+P: show command • Esc: back • ?: hide help")
 	case 2: // Options list
-		return help.Render("Space: toggle • D: delete • R: reset • ↑/↓: navigate • Esc: back • ?: hide help")
+		return help.Render("Space: toggle • D: delete • R: reset • O: output • ↑/↓: navigate • Ctrl+P: show command • Esc: back • ?: hide help")
 	case 3: // New preset name
 		return help.Render("Enter: create • Esc: cancel • ?: hide help")
 	default:
@@ -442,7 +957,9 @@ func getAddOptionHelpText(inputFocus int, showHelp bool) string {
 	}
 
 	switch inputFocus {
-	case 0, 1: // Input fields
+	case 0: // Flag input
+		return help.Render("Tab: complete • Ctrl+N/Ctrl+P: next/previous match • ↑/↓: navigate • Esc: cancel • ?: hide help")
+	case 1: // Comment input
 		return help.Render("Enter: add option • ↑/↓: navigate • Esc: cancel • ?: hide help")
 	case 2: // Add button
 		return help.Render("Enter: add option • ↑/↓: navigate • Esc: cancel • ?: hide help")
@@ -453,13 +970,40 @@ func getAddOptionHelpText(inputFocus int, showHelp bool) string {
 	}
 }
 
+// getDownloadsHelpText returns help text for the downloads dashboard
+func getDownloadsHelpText(showHelp bool) string {
+	help := lipgloss.NewStyle().Faint(true)
+	if showHelp {
+		return help.Render("↑/↓: select download • x: cancel • p: pause • r: resume • s: schedule • Esc: back • ?: hide help")
+	}
+	return help.Render("?: help")
+}
+
+// getFormatHelpText returns help text for the format picker
+func getFormatHelpText(showHelp bool) string {
+	help := lipgloss.NewStyle().Faint(true)
+	if showHelp {
+		return help.Render("Space: mark video/audio format • Enter: use format • /: filter • Esc: cancel • ?: hide help")
+	}
+	return help.Render("?: help")
+}
+
+// getPlaylistHelpText returns help text for the playlist entries view
+func getPlaylistHelpText(showHelp bool) string {
+	help := lipgloss.NewStyle().Faint(true)
+	if showHelp {
+		return help.Render("Space: toggle • A: select all/none • Enter: queue selected • /: filter • Esc: cancel • ?: hide help")
+	}
+	return help.Render("?: help")
+}
+
 func getURLHelpText(showHelp bool) string {
 	help := lipgloss.NewStyle().Faint(true)
 	// Always show Esc: quit and ? toggle text; when expanded, add details
 	if !showHelp {
 		return help.Render("Esc: quit • ?: help")
 	}
-	return help.Render("Esc: quit • Enter: download • →/←: switch button • ?: hide help")
+	return help.Render("Esc: quit • Enter: queue download • Ctrl+F: pick format • Ctrl+O: import file • Ctrl+V: paste links • Ctrl+P: show command • →/←: switch button • ?: hide help")
 }
 
 // Simple styles - no complex borders needed
@@ -486,6 +1030,24 @@ func main() {
 	// Parse CLI arguments (skip program name)
 	cliArgs = os.Args[1:]
 
+	// Load application settings
+	settings, settingsErr := LoadSettings()
+	if settingsErr != nil {
+		logToFile("Failed to load settings: " + settingsErr.Error())
+	}
+	appSettings = settings
+
+	// CLI modes have no status line to show invalid settings in
+	if settingsErr != nil && len(cliArgs) > 0 {
+		fmt.Printf("Warning: %v\n", settingsErr)
+	}
+
+	// Batch mode reads URLs from a file or stdin
+	if len(cliArgs) > 0 && cliArgs[0] == "batch" {
+		runBatch(cliArgs[1:])
+		return
+	}
+
 	// If CLI arguments are provided, run yt-dlp directly without TUI
 	if len(cliArgs) > 0 {
 		runDirectYtDlp(cliArgs)
@@ -494,11 +1056,20 @@ func main() {
 
 	// Initialize model for TUI mode
 	model := initialModel()
+	if settingsErr != nil {
+		model.SettingsError = settingsErr.Error()
+	}
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	// Run the program
-	_, err := p.Run()
+	finalModel, err := p.Run()
+
+	// Stop running downloads, the saved queue continues them next time
+	if final, ok := finalModel.(Model); ok {
+		final.Queue.Stop()
+	}
+
 	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
@@ -510,70 +1081,120 @@ func runDirectYtDlp(args []string) {
 	// Log CLI execution mode
 	logToFile("Running in CLI mode with args: " + strings.Join(args, " "))
 
-	// Find URL in arguments (first argument that looks like a URL)
-	var url string
-	var nonUrlArgs []string
+	// Split arguments into yt-dlp options and URLs
+	catalog := cliCatalog()
+	options, positional := parseCLIArgs(args, catalog)
 
-	for _, arg := range args {
+	var urls []string
+	for _, arg := range positional {
 		if isValidURL(arg) {
-			url = arg
+			urls = append(urls, arg)
 		} else {
-			nonUrlArgs = append(nonUrlArgs, arg)
+			fmt.Printf("Warning: ignoring %q, it's neither a URL nor the value of an option\n", arg)
 		}
 	}
 
-	if url == "" {
+	if len(urls) == 0 {
 		fmt.Println("Error: No valid URL found in arguments")
-		fmt.Println("Usage: babago [URL] [yt-dlp options...]")
+		fmt.Println("Usage: babago [URL...] [yt-dlp options...]")
+		fmt.Println("       babago batch FILE|- [yt-dlp options...]")
+		os.Exit(1)
+	}
+
+	if failed := runURLsDirect(urls, options, catalog); failed > 0 {
 		os.Exit(1)
 	}
+}
 
+// runURLsDirect downloads every URL one after another and returns the number of failures
+func runURLsDirect(urls []string, cliOptions []Option, catalog *OptionCatalog) int {
 	// Load saved configuration
 	presetsView := NewPresetsView()
+	presetsView.Catalog = catalog
 
-	// Get merged options (saved config + CLI args)
-	mergedOptions := presetsView.GetMergedOptions(nonUrlArgs)
+	// Get merged options (saved config + CLI args), one download at a time gets the whole rate limit
+	mergedOptions := presetsView.GetMergedOptions(cliOptions)
+	if rate, _ := parseRate(appSettings.RateLimit); rate > 0 {
+		mergedOptions = applyRateLimit(mergedOptions, rate)
+	}
 
-	// Execute yt-dlp directly
-	runYtDlpDirect(url, mergedOptions)
-}
+	// The archive and hooks need yt-dlp to report the downloaded files to a file, as its output goes to the terminal
+	archivePath := presetsView.ArchivePath()
+	hooks := presetsView.ActiveHooks()
+	var reportPath string
+	if archivePath != "" || len(hooks) > 0 {
+		report, err := os.CreateTemp("", "babago-report-*.txt")
+		if err != nil {
+			fmt.Printf("Warning: downloads aren't recorded in the archive and hooks are skipped: %v\n", err)
+			archivePath = ""
+			hooks = nil
+		} else {
+			report.Close()
+			reportPath = report.Name()
+			defer os.Remove(reportPath)
+		}
+	}
 
-// generateVideoName generates a simple name for video based on URL
-func generateVideoName(url string) string {
-	// Simple name generation based on URL
-	if strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be") {
-		// Extract video ID from YouTube URL
-		if strings.Contains(url, "v=") {
-			parts := strings.Split(url, "v=")
-			if len(parts) > 1 {
-				videoID := strings.Split(parts[1], "&")[0]
-				if len(videoID) > 8 {
-					return "YouTube_" + videoID[:8]
-				}
+	failed := 0
+	for i, url := range urls {
+		if len(urls) > 1 {
+			fmt.Printf("[%d/%d] %s\n", i+1, len(urls), url)
+		}
+
+		// Execute yt-dlp directly
+		var extraArgs []string
+		if reportPath != "" {
+			// Start every download with an empty report
+			if err := os.WriteFile(reportPath, nil, 0600); err != nil {
+				logToFile("Failed to clear download report: " + err.Error())
 			}
-		} else if strings.Contains(url, "youtu.be/") {
-			parts := strings.Split(url, "youtu.be/")
-			if len(parts) > 1 {
-				videoID := strings.Split(parts[1], "?")[0]
-				if len(videoID) > 8 {
-					return "YouTube_" + videoID[:8]
-				}
+			extraArgs = reportArgs("--print-to-file", reportPath)
+		}
+		if err := runYtDlpDirect(url, mergedOptions, extraArgs...); err != nil {
+			fmt.Printf("Error executing yt-dlp: %v\n", err)
+			failed++
+			continue
+		}
+		if archivePath != "" {
+			if err := recordReport(archivePath, url, reportPath); err != nil {
+				fmt.Printf("Warning: couldn't update the download archive: %v\n", err)
 			}
 		}
-		return "YouTube_video"
+		if len(hooks) > 0 {
+			runHooksDirect(url, hooks, presetsView.ActivePresetNames(), reportPath)
+		}
 	}
 
-	// For other URLs, use domain
-	if strings.Contains(url, "://") {
-		parts := strings.Split(url, "://")
-		if len(parts) > 1 {
-			domain := strings.Split(parts[1], "/")[0]
-			domain = strings.Replace(domain, "www.", "", 1)
-			return strings.Title(domain) + "_video"
+	if len(urls) > 1 {
+		fmt.Printf("Finished: %d succeeded, %d failed\n", len(urls)-failed, failed)
+	}
+	return failed
+}
+
+// generateVideoName generates a simple name for video based on URL
+func generateVideoName(rawURL string) string {
+	host := urlHost(rawURL)
+	if host == "" {
+		return "video"
+	}
+
+	// Name YouTube videos after the start of their ID
+	if hostMatches(host, "youtube.com") || host == "youtu.be" {
+		videoID := ""
+		if parsed, err := url.Parse(rawURL); err == nil {
+			videoID = parsed.Query().Get("v")
+			if host == "youtu.be" {
+				videoID = strings.Trim(parsed.Path, "/")
+			}
 		}
+		if len(videoID) > 8 {
+			return "YouTube_" + videoID[:8]
+		}
+		return "YouTube_video"
 	}
 
-	return "video"
+	// For other URLs, use domain
+	return strings.Title(host) + "_video"
 }
 
 func logToFile(msg string) {
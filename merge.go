@@ -0,0 +1,98 @@
+package main
+
+import "strings"
+
+// OptionConflict describes options of several presets setting the same flag
+type OptionConflict struct {
+	Key      string
+	Winner   Option   // Option that is passed to yt-dlp
+	Shadowed []Option // Options it overrides, in the order they were merged
+}
+
+// optionMerge merges options by flag, later options overriding earlier ones.
+// Flags keep the position they were first seen at, so argv order is stable.
+type optionMerge struct {
+	catalog  *OptionCatalog // Resolves aliases and repeatable options, may be nil
+	order    []string
+	options  map[string]Option
+	shadowed map[string][]Option
+}
+
+// newOptionMerge creates an empty merge
+func newOptionMerge(catalog *OptionCatalog) *optionMerge {
+	return &optionMerge{
+		catalog:  catalog,
+		options:  make(map[string]Option),
+		shadowed: make(map[string][]Option),
+	}
+}
+
+// flagAliases maps short flags to the long flags they are merged with when no catalog is loaded
+var flagAliases = map[string]string{
+	"-f": "--format",
+	"-o": "--output",
+	"-P": "--paths",
+	"-x": "--extract-audio",
+	"-r": "--limit-rate",
+}
+
+// flagKey returns the long flag an argument sets, e.g. "--format" for "--format=best" and "-f"
+func flagKey(arg string) string {
+	name, _, _ := strings.Cut(arg, "=")
+	if long, ok := flagAliases[name]; ok {
+		return long
+	}
+	return name
+}
+
+// optionKey returns the flag an option sets, e.g. "--format" for "--format=best" and "-f best".
+// Options that can be given several times, like --print, are only merged with identical ones.
+func (m *optionMerge) optionKey(option Option) string {
+	args := option.Arguments()
+	if len(args) == 0 {
+		return ""
+	}
+	name, catalogOption, values, ok := m.catalog.splitFlag(args)
+	if !ok {
+		// Without the catalog only the common aliases are known
+		return flagKey(name)
+	}
+	if catalogOption.Repeatable() {
+		return shellJoin(append([]string{catalogOption.Name}, values...))
+	}
+	return catalogOption.Name
+}
+
+// add merges an option, overriding an earlier option with the same flag
+func (m *optionMerge) add(option Option) {
+	key := m.optionKey(option)
+	if key == "" {
+		return
+	}
+	if previous, ok := m.options[key]; ok {
+		m.shadowed[key] = append(m.shadowed[key], previous)
+	} else {
+		m.order = append(m.order, key)
+	}
+	m.options[key] = option
+}
+
+// result returns the winning options in a stable order
+func (m *optionMerge) result() []Option {
+	var options []Option
+	for _, key := range m.order {
+		options = append(options, m.options[key])
+	}
+	return options
+}
+
+// conflicts returns every flag set more than once, in argv order
+func (m *optionMerge) conflicts() []OptionConflict {
+	var conflicts []OptionConflict
+	for _, key := range m.order {
+		if shadowed := m.shadowed[key]; len(shadowed) > 0 {
+			conflicts = append(conflicts, OptionConflict{Key: key, Winner: m.options[key], Shadowed: shadowed})
+		}
+	}
+	return conflicts
+}
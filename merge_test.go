@@ -0,0 +1,122 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOptionMerge(t *testing.T) {
+	merge := newOptionMerge(nil)
+	merge.add(Option{Flag: "--format best", Source: "Best"})
+	merge.add(Option{Flag: "--embed-subs", Source: "Best"})
+	merge.add(Option{Flag: "-o %(id)s.%(ext)s", Source: "Best"})
+	merge.add(Option{Flag: "", Source: "Empty"})
+	merge.add(Option{Flag: "-f bestaudio", Source: "Audio"})
+	merge.add(Option{Flag: "--format=worst", Source: "Small"})
+	merge.add(Option{Flag: "--output %(title)s.%(ext)s", Source: "Audio"})
+
+	var got []string
+	for _, option := range merge.result() {
+		got = append(got, option.Flag)
+	}
+	want := []string{"--format=worst", "--embed-subs", "--output %(title)s.%(ext)s"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result() = %q, want %q", got, want)
+	}
+
+	conflicts := merge.conflicts()
+	if len(conflicts) != 2 {
+		t.Fatalf("conflicts() = %+v, want --format and --output", conflicts)
+	}
+	if conflicts[0].Key != "--format" || conflicts[0].Winner.Source != "Small" || len(conflicts[0].Shadowed) != 2 ||
+		conflicts[0].Shadowed[0].Source != "Best" || conflicts[0].Shadowed[1].Source != "Audio" {
+		t.Errorf("--format conflict = %+v, want Small overriding Best and Audio", conflicts[0])
+	}
+	if conflicts[1].Key != "--output" || conflicts[1].Winner.Source != "Audio" || len(conflicts[1].Shadowed) != 1 {
+		t.Errorf("--output conflict = %+v, want Audio overriding Best", conflicts[1])
+	}
+}
+
+func TestGetMergedOptionsAliases(t *testing.T) {
+	pv := PresetsView{Presets: []Preset{
+		{Name: "Best", Active: true, Options: []Option{
+			{Flag: "-f best", Enabled: true},
+			{Flag: "-x", Enabled: true},
+			{Flag: "-r 1M", Enabled: true},
+		}},
+	}}
+
+	got := buildYtDlpArgs("https://example.com", pv.GetMergedOptions(cliOptions([]string{"--format=worst", "--limit-rate", "500K", "--extract-audio"})))
+	want := []string{"https://example.com", "--format=worst", "--extract-audio", "--limit-rate", "500K"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged argv = %q, want %q", got, want)
+	}
+}
+
+func TestPresetsViewConflicts(t *testing.T) {
+	pv := PresetsView{Presets: []Preset{
+		{Name: "Best", Active: true, Options: []Option{{Flag: "-f best", Enabled: true}}},
+		{Name: "Off", Active: false, Options: []Option{{Flag: "-f worst", Enabled: true}}},
+		{Name: "Disabled", Active: true, Options: []Option{{Flag: "-f worst", Enabled: false}}},
+		{Name: "Audio", Active: true, Options: []Option{{Flag: "--format bestaudio", Enabled: true}}},
+	}}
+
+	conflicts := pv.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Winner.Source != "Audio" || len(conflicts[0].Shadowed) != 1 || conflicts[0].Shadowed[0].Source != "Best" {
+		t.Errorf("Conflicts() = %+v, want Audio overriding Best", conflicts)
+	}
+}
+
+func newTestPresetsView(t *testing.T) PresetsView {
+	t.Setenv("HOME", t.TempDir())
+	pv := NewPresetsView()
+	pv.Presets = []Preset{{Name: "One"}, {Name: "Two"}, {Name: "Three"}}
+	pv.updateListItems()
+	pv.List.SetSize(80, 40)
+	return pv
+}
+
+func presetNames(presets []Preset) []string {
+	var names []string
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+func TestPresetsViewMovePreset(t *testing.T) {
+	pv := newTestPresetsView(t)
+
+	pv.List.Select(0)
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'J'}})
+	if got := presetNames(pv.Presets); !reflect.DeepEqual(got, []string{"Two", "One", "Three"}) {
+		t.Errorf("after J presets = %q", got)
+	}
+	if pv.List.Index() != 1 {
+		t.Errorf("moved preset isn't selected, index %d", pv.List.Index())
+	}
+
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	if got := presetNames(pv.Presets); !reflect.DeepEqual(got, []string{"One", "Two", "Three"}) {
+		t.Errorf("after K at the top presets = %q", got)
+	}
+}
+
+func TestPresetsViewMovePresetWhileFiltered(t *testing.T) {
+	pv := newTestPresetsView(t)
+
+	pv.List.SetFilterText("Two")
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'J'}})
+	if got := presetNames(pv.Presets); !reflect.DeepEqual(got, []string{"One", "Two", "Three"}) {
+		t.Errorf("presets moved while filtered: %q", got)
+	}
+}
+
+// cliOptions parses command line arguments without a catalog
+func cliOptions(args []string) []Option {
+	options, _ := parseCLIArgs(args, nil)
+	return options
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// metadataDebounce is how long typing has to pause before metadata is fetched
+const metadataDebounce = 600 * time.Millisecond
+
+// metadataTimeout limits how long a single yt-dlp -J call may take
+const metadataTimeout = 60 * time.Second
+
+// VideoMetadata holds the parts of yt-dlp's -J output used by babago
+type VideoMetadata struct {
+	Type             string          `json:"_type"` // "playlist" for playlists and channels
+	ID               string          `json:"id"`
+	Title            string          `json:"title"`
+	Uploader         string          `json:"uploader"`
+	Channel          string          `json:"channel"`
+	Duration         float64         `json:"duration"`
+	UploadDate       string          `json:"upload_date"` // YYYYMMDD
+	Filesize         int64           `json:"filesize"`
+	FilesizeApprox   int64           `json:"filesize_approx"`
+	Extractor        string          `json:"extractor"`
+	ExtractorKey     string          `json:"extractor_key"`
+	WebpageURL       string          `json:"webpage_url"`
+	Ext              string          `json:"ext"`
+	RequestedFormats []VideoMetadata `json:"requested_formats"`
+	Formats          []VideoFormat   `json:"formats"`
+	Entries          []PlaylistEntry `json:"entries"`
+}
+
+// PlaylistEntry is a single flat entry of a playlist or channel
+type PlaylistEntry struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	WebpageURL string  `json:"webpage_url"`
+	Duration   float64 `json:"duration"`
+	Uploader   string  `json:"uploader"`
+	Channel    string  `json:"channel"`
+}
+
+// IsPlaylist reports whether the metadata describes a playlist or channel
+func (v VideoMetadata) IsPlaylist() bool {
+	return v.Type == "playlist"
+}
+
+// EntryURL returns the URL a playlist entry can be downloaded from
+func (e PlaylistEntry) EntryURL() string {
+	if isValidURL(e.URL) {
+		return e.URL
+	}
+	return e.WebpageURL
+}
+
+// VideoFormat is a single entry of the formats yt-dlp can download
+type VideoFormat struct {
+	FormatID       string  `json:"format_id"`
+	FormatNote     string  `json:"format_note"`
+	Ext            string  `json:"ext"`
+	Resolution     string  `json:"resolution"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	FPS            float64 `json:"fps"`
+	VCodec         string  `json:"vcodec"`
+	ACodec         string  `json:"acodec"`
+	TBR            float64 `json:"tbr"`
+	Filesize       int64   `json:"filesize"`
+	FilesizeApprox int64   `json:"filesize_approx"`
+}
+
+// HasVideo reports whether the format contains a video stream.
+// Extractors often leave the codec out, only "none" means there's no stream.
+func (f VideoFormat) HasVideo() bool {
+	return f.VCodec != "none"
+}
+
+// HasAudio reports whether the format contains an audio stream.
+// Extractors often leave the codec out, only "none" means there's no stream.
+func (f VideoFormat) HasAudio() bool {
+	return f.ACodec != "none"
+}
+
+// Size returns the exact or approximate size of the format in bytes, or 0 if unknown
+func (f VideoFormat) Size() int64 {
+	if f.Filesize > 0 {
+		return f.Filesize
+	}
+	return f.FilesizeApprox
+}
+
+// MetadataEntry is the prefetch state of a single URL
+type MetadataEntry struct {
+	Loading bool
+	Info    *VideoMetadata
+	Err     string
+}
+
+// metadataFlags are the options yt-dlp needs to reach a video at all,
+// so the metadata prefetch uses them too
+var metadataFlags = map[string]bool{
+	"--cookies":              true,
+	"--cookies-from-browser": true,
+	"--proxy":                true,
+	"--user-agent":           true,
+	"--referer":              true,
+	"--add-headers":          true,
+	"--username":             true,
+	"-u":                     true,
+	"--password":             true,
+	"-p":                     true,
+	"--netrc":                true,
+	"--netrc-location":       true,
+}
+
+// metadataDebounceMsg is sent once typing has paused on a URL
+type metadataDebounceMsg struct {
+	URL  string
+	Args []string // Authentication and network options of the active presets
+}
+
+// MetadataMsg is sent when a metadata prefetch finishes
+type MetadataMsg struct {
+	URL  string
+	Info *VideoMetadata
+	Err  error
+}
+
+// debounceMetadataCmd waits a moment before asking for metadata of url
+func debounceMetadataCmd(url string) tea.Cmd {
+	return tea.Tick(metadataDebounce, func(time.Time) tea.Msg {
+		return metadataDebounceMsg{URL: url}
+	})
+}
+
+// metadataArgs picks the authentication and network options out of options
+func metadataArgs(options []Option) []string {
+	var args []string
+	for _, option := range options {
+		flagParts := option.Arguments()
+		if !option.Enabled || len(flagParts) == 0 {
+			continue
+		}
+		name, _, _ := strings.Cut(flagParts[0], "=")
+		if metadataFlags[name] {
+			args = append(args, flagParts...)
+		}
+	}
+	return args
+}
+
+// fetchMetadataCmd runs yt-dlp -J in the background for url
+func fetchMetadataCmd(url string, args []string) tea.Cmd {
+	return func() tea.Msg {
+		info, err := fetchMetadata(url, args)
+		return MetadataMsg{URL: url, Info: info, Err: err}
+	}
+}
+
+// fetchMetadata asks yt-dlp for the metadata of url without downloading anything.
+// args are passed along, so cookies and proxies apply to the prefetch as well.
+func fetchMetadata(url string, args []string) (*VideoMetadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+
+	logToFile("Fetching metadata: " + url)
+
+	// --flat-playlist keeps playlists and channels fast, single videos are unaffected
+	args = append([]string{"-J", "--skip-download", "--flat-playlist", "--no-warnings"}, args...)
+	cmd := ytDlpCommandContext(ctx, append(args, url)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s", lastLine(message))
+		}
+		return nil, err
+	}
+
+	var info VideoMetadata
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// EstimatedSize returns the expected download size in bytes, or 0 if unknown
+func (v VideoMetadata) EstimatedSize() int64 {
+	if v.Filesize > 0 {
+		return v.Filesize
+	}
+	if v.FilesizeApprox > 0 {
+		return v.FilesizeApprox
+	}
+
+	// Merged downloads report sizes per format
+	var total int64
+	for _, format := range v.RequestedFormats {
+		total += format.EstimatedSize()
+	}
+	return total
+}
+
+// Summary returns a one-line description of uploader, duration, date and size
+func (v VideoMetadata) Summary() string {
+	var parts []string
+
+	uploader := v.Uploader
+	if uploader == "" {
+		uploader = v.Channel
+	}
+	if uploader != "" {
+		parts = append(parts, uploader)
+	}
+	if v.IsPlaylist() {
+		parts = append(parts, fmt.Sprintf("%d entries", len(v.Entries)))
+	}
+	if v.Duration > 0 {
+		parts = append(parts, formatDuration(v.Duration))
+	}
+	if v.UploadDate != "" {
+		parts = append(parts, formatUploadDate(v.UploadDate))
+	}
+	if size := v.EstimatedSize(); size > 0 {
+		parts = append(parts, "~"+formatBytes(size))
+	}
+
+	return strings.Join(parts, " • ")
+}
+
+// formatDuration formats seconds as H:MM:SS or M:SS
+func formatDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	hours, minutes, secs := total/3600, (total%3600)/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, secs)
+}
+
+// formatUploadDate turns yt-dlp's YYYYMMDD into YYYY-MM-DD
+func formatUploadDate(date string) string {
+	parsed, err := time.Parse("20060102", date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("2006-01-02")
+}
+
+// formatBytes formats a size in bytes using binary units, like yt-dlp does
+func formatBytes(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// lastLine returns the last non-empty line of text
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
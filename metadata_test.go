@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadataArgs(t *testing.T) {
+	options := []Option{
+		{Flag: "--cookies-from-browser firefox", Enabled: true},
+		{Flag: "-f bestaudio", Enabled: true},
+		{Flag: "--proxy=socks5://127.0.0.1:9050", Enabled: true},
+		{Flag: "--user-agent Mozilla/5.0", Enabled: false},
+		{Flag: "-x", Enabled: true},
+		{Flag: "--cookies cookies.txt", Enabled: true},
+		{Flag: `--add-headers "Referer: https://example.com"`, Enabled: true},
+	}
+
+	want := []string{"--cookies-from-browser", "firefox", "--proxy=socks5://127.0.0.1:9050", "--cookies", "cookies.txt", "--add-headers", "Referer: https://example.com"}
+	if got := metadataArgs(options); !reflect.DeepEqual(got, want) {
+		t.Errorf("metadataArgs() = %q, want %q", got, want)
+	}
+	if got := metadataArgs(nil); got != nil {
+		t.Errorf("metadataArgs(nil) = %q, want nil", got)
+	}
+}
+
+func TestVideoFormatStreams(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    VideoFormat
+		wantVideo bool
+		wantAudio bool
+	}{
+		{"combined", VideoFormat{VCodec: "avc1", ACodec: "mp4a"}, true, true},
+		{"video only", VideoFormat{VCodec: "vp9", ACodec: "none"}, true, false},
+		{"audio only", VideoFormat{VCodec: "none", ACodec: "opus"}, false, true},
+		{"unknown codecs", VideoFormat{}, true, true},
+		{"storyboard", VideoFormat{VCodec: "none", ACodec: "none"}, false, false},
+	}
+
+	for _, test := range tests {
+		if got := test.format.HasVideo(); got != test.wantVideo {
+			t.Errorf("%s: HasVideo() = %v, want %v", test.name, got, test.wantVideo)
+		}
+		if got := test.format.HasAudio(); got != test.wantAudio {
+			t.Errorf("%s: HasAudio() = %v, want %v", test.name, got, test.wantAudio)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpTimeout limits how long yt-dlp --help may take
+const helpTimeout = 15 * time.Second
+
+// maxOptionIndent is the deepest indentation of an option line in yt-dlp --help,
+// deeper lines continue the help text of the option above
+const maxOptionIndent = 8
+
+// CatalogOption is an option of yt-dlp as listed by yt-dlp --help
+type CatalogOption struct {
+	Name    string   `json:"name"`              // Long flag, e.g. --format
+	Aliases []string `json:"aliases,omitempty"` // Other spellings, e.g. -f
+	Value   string   `json:"value,omitempty"`   // Names of the values it takes, empty for switches
+	Help    string   `json:"help"`
+}
+
+// OptionCatalog lists every option of one yt-dlp version
+type OptionCatalog struct {
+	Version string          `json:"version"`
+	Options []CatalogOption `json:"options"`
+
+	flags map[string]int // Index of the option for each name and alias
+}
+
+// OptionCatalogMsg is sent when the option catalog is loaded
+type OptionCatalogMsg struct {
+	Catalog *OptionCatalog
+}
+
+// Arity returns the number of values the option takes
+func (o CatalogOption) Arity() int {
+	return len(strings.Fields(o.Value))
+}
+
+// accumulatingOptions are the yt-dlp options known to add a value every time they're given
+// instead of replacing the previous one. --output and --paths are left out on purpose,
+// preset output settings are meant to replace them.
+var accumulatingOptions = map[string]bool{
+	"--add-headers":         true,
+	"--alias":               true,
+	"--break-match-filters": true,
+	"--download-sections":   true,
+	"--downloader":          true,
+	"--downloader-args":     true,
+	"--exec":                true,
+	"--extractor-args":      true,
+	"--match-filters":       true,
+	"--parse-metadata":      true,
+	"--postprocessor-args":  true,
+	"--print":               true,
+	"--print-to-file":       true,
+	"--replace-in-metadata": true,
+	"--use-postprocessor":   true,
+}
+
+// Repeatable reports whether the option can be given several times, each adding a value
+// instead of replacing the previous one, like --print. Options missing from
+// accumulatingOptions are repeatable if their help text says so.
+func (o CatalogOption) Repeatable() bool {
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		if accumulatingOptions[name] {
+			return true
+		}
+	}
+	return strings.Contains(o.Help, "multiple times")
+}
+
+// Summary returns the first sentence of the help text
+func (o CatalogOption) Summary() string {
+	summary := o.Help
+	if end := strings.Index(summary, ". "); end >= 0 {
+		summary = summary[:end]
+	}
+	return strings.TrimSuffix(summary, ".")
+}
+
+// Usage returns the flag with the names of its values, e.g. "--format FORMAT"
+func (o CatalogOption) Usage() string {
+	if o.Value == "" {
+		return o.Name
+	}
+	return o.Name + " " + o.Value
+}
+
+// getOptionCatalogPath returns the path of the cached option catalog next to config.json
+func getOptionCatalogPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "options.json"), nil
+}
+
+// loadOptionCatalogCmd loads the option catalog of the given yt-dlp version in the background
+func loadOptionCatalogCmd(version string) tea.Cmd {
+	return func() tea.Msg {
+		catalog, err := LoadOptionCatalog(version)
+		if err != nil {
+			logToFile("Failed to load yt-dlp options: " + err.Error())
+			return OptionCatalogMsg{}
+		}
+		return OptionCatalogMsg{Catalog: catalog}
+	}
+}
+
+// LoadOptionCatalog returns the options of the given yt-dlp version. The catalog is
+// cached, yt-dlp --help only runs again when the version changes.
+func LoadOptionCatalog(version string) (*OptionCatalog, error) {
+	path, err := getOptionCatalogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var cached OptionCatalog
+		if err := json.Unmarshal(data, &cached); err != nil {
+			logToFile("Ignoring cached yt-dlp options: " + err.Error())
+		} else if cached.Version == version && len(cached.Options) > 0 {
+			cached.index()
+			return &cached, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), helpTimeout)
+	defer cancel()
+
+	output, err := ytDlpCommandContext(ctx, "--help").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s --help failed: %v", ytDlpCommandLine(), err)
+	}
+
+	catalog := parseYtDlpHelp(string(output))
+	if len(catalog.Options) == 0 {
+		return nil, fmt.Errorf("%s --help lists no options", ytDlpCommandLine())
+	}
+	catalog.Version = version
+	logToFile(fmt.Sprintf("Parsed %d yt-dlp options", len(catalog.Options)))
+
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		logToFile("Failed to cache yt-dlp options: " + err.Error())
+	}
+	return catalog, nil
+}
+
+// parseYtDlpHelp reads the options from the output of yt-dlp --help, e.g.
+//
+//	-f, --format FORMAT             Video format code, see "FORMAT
+//	                                SELECTION" for more details
+func parseYtDlpHelp(text string) *OptionCatalog {
+	catalog := &OptionCatalog{}
+	var current *CatalogOption
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case trimmed == "":
+			current = nil
+		case indent > maxOptionIndent:
+			// Help text of the option above
+			if current != nil {
+				current.Help = strings.TrimSpace(current.Help + " " + trimmed)
+			}
+		case strings.HasPrefix(trimmed, "-") && indent > 0:
+			usage, help, _ := strings.Cut(trimmed, "  ")
+			option, ok := parseOptionUsage(usage)
+			if !ok {
+				current = nil
+				continue
+			}
+			option.Help = strings.TrimSpace(help)
+			catalog.Options = append(catalog.Options, option)
+			current = &catalog.Options[len(catalog.Options)-1]
+		default:
+			// Usage line or section heading
+			current = nil
+		}
+	}
+
+	catalog.index()
+	return catalog
+}
+
+// parseOptionUsage parses the flags and values of an option, e.g. "-f, --format FORMAT"
+func parseOptionUsage(usage string) (CatalogOption, bool) {
+	var names []string
+	var value string
+	for _, part := range strings.Split(usage, ", ") {
+		name, rest, _ := strings.Cut(part, " ")
+		if !strings.HasPrefix(name, "-") {
+			return CatalogOption{}, false
+		}
+		names = append(names, name)
+		if rest != "" {
+			value = strings.TrimSpace(rest)
+		}
+	}
+
+	// The long flag is the name, e.g. -f, --format
+	option := CatalogOption{Name: names[len(names)-1], Value: value}
+	for _, name := range names {
+		if name != option.Name {
+			option.Aliases = append(option.Aliases, name)
+		}
+	}
+	return option, true
+}
+
+// index maps every name and alias to its option
+func (c *OptionCatalog) index() {
+	c.flags = make(map[string]int)
+	for i, option := range c.Options {
+		c.flags[option.Name] = i
+		for _, alias := range option.Aliases {
+			c.flags[alias] = i
+		}
+	}
+}
+
+// Lookup returns the option with the given name or alias, like --format or -f
+func (c *OptionCatalog) Lookup(flag string) (CatalogOption, bool) {
+	if c == nil {
+		return CatalogOption{}, false
+	}
+	i, ok := c.flags[flag]
+	if !ok {
+		return CatalogOption{}, false
+	}
+	return c.Options[i], true
+}
+
+// Complete returns the options whose name starts with prefix, in the order of yt-dlp --help
+func (c *OptionCatalog) Complete(prefix string) []CatalogOption {
+	if c == nil || prefix == "" {
+		return nil
+	}
+	var matches []CatalogOption
+	for _, option := range c.Options {
+		if strings.HasPrefix(option.Name, prefix) {
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
+
+// Closest returns the long flag most similar to flag, for "did you mean" hints
+func (c *OptionCatalog) Closest(flag string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	best, bestDistance := "", 0
+	for _, option := range c.Options {
+		distance := editDistance(flag, option.Name)
+		if best == "" || distance < bestDistance {
+			best, bestDistance = option.Name, distance
+		}
+	}
+	// Only suggest flags that are a typo away
+	if best == "" || bestDistance > max(2, len(flag)/4) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// splitFlag splits the arguments of an option into the flag as written and its values,
+// e.g. "--format=best" or "-fbest" into "--format" or "-f" and "best".
+// It returns false if the flag isn't a yt-dlp option.
+func (c *OptionCatalog) splitFlag(args []string) (string, CatalogOption, []string, bool) {
+	name, value, hasValue := strings.Cut(args[0], "=")
+	option, ok := c.Lookup(name)
+	if !ok && !strings.HasPrefix(name, "--") && len(name) > 2 {
+		// Short flag with the value attached, e.g. -fbest
+		if short, found := c.Lookup(name[:2]); found && short.Arity() == 1 {
+			option, ok = short, true
+			name, value, hasValue = name[:2], name[2:], true
+		}
+	}
+
+	values := args[1:]
+	if hasValue {
+		values = append([]string{value}, values...)
+	}
+	return name, option, values, ok
+}
+
+// CheckFlag reports problems with an option flag: unknown options and missing or extra values.
+// It returns an empty string if the flag looks right or the catalog isn't loaded.
+func (c *OptionCatalog) CheckFlag(flag string) string {
+	if c == nil {
+		return ""
+	}
+	args, err := splitShellWords(flag)
+	if err != nil || len(args) == 0 {
+		return ""
+	}
+
+	name, option, values, ok := c.splitFlag(args)
+	if !ok {
+		if closest, found := c.Closest(name); found {
+			return fmt.Sprintf("Unknown yt-dlp option %s, did you mean %s?", name, closest)
+		}
+		return fmt.Sprintf("Unknown yt-dlp option %s", name)
+	}
+
+	switch arity := option.Arity(); {
+	case len(values) < arity:
+		return fmt.Sprintf("%s expects %s", name, option.Value)
+	case arity == 0 && len(values) > 0:
+		return fmt.Sprintf("%s doesn't take a value", name)
+	case arity == 1 && len(values) > 1:
+		return fmt.Sprintf("%s takes one value, got %d", name, len(values))
+	case len(values) > arity:
+		return fmt.Sprintf("%s takes %d values, got %d", name, arity, len(values))
+	}
+	return ""
+}
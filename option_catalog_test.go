@@ -0,0 +1,299 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCatalogOptionRepeatable(t *testing.T) {
+	tests := []struct {
+		name   string
+		option CatalogOption
+		want   bool
+	}{
+		{
+			"known option with reworded help",
+			CatalogOption{Name: "--print", Aliases: []string{"-O"}, Value: "[WHEN:]TEMPLATE", Help: "Field name or output template to print to screen"},
+			true,
+		},
+		{
+			"known option by alias",
+			CatalogOption{Name: "--postprocessor-args", Aliases: []string{"--ppa"}, Value: "NAME:ARGS", Help: "Give these arguments to the postprocessors"},
+			true,
+		},
+		{
+			"unknown option whose help says so",
+			CatalogOption{Name: "--new-filter", Value: "FILTER", Help: "Skip videos. This option can be used multiple times"},
+			true,
+		},
+		{
+			"single option",
+			CatalogOption{Name: "--format", Aliases: []string{"-f"}, Value: "FORMAT", Help: "Video format code"},
+			false,
+		},
+		{
+			"output template replaces earlier ones",
+			CatalogOption{Name: "--output", Aliases: []string{"-o"}, Value: "[TYPES:]TEMPLATE", Help: "Output filename template"},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.option.Repeatable(); got != test.want {
+			t.Errorf("%s: Repeatable() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// testHelp is an excerpt of yt-dlp --help with wrapped help text
+const testHelp = `Usage: yt-dlp [OPTIONS] URL [URL...]
+
+Options:
+  General Options:
+    -h, --help                      Print this help text and exit
+    -i, --ignore-errors             Ignore download and postprocessing errors.
+                                    The download will be considered successful
+                                    even if the postprocessing fails
+
+  Download Options:
+    -r, --limit-rate RATE           Maximum download rate in bytes per second,
+                                    e.g. 50K or 4.2M
+    --no-part                       Do not use .part files - write directly into
+                                    output file
+
+  Verbosity and Simulation Options:
+    -O, --print [WHEN:]TEMPLATE     Field name or output template to print to
+                                    screen. This option can be used multiple
+                                    times
+    --print-to-file [WHEN:]TEMPLATE FILE
+                                    Append given template to the file. This
+                                    option can be used multiple times
+
+  Video Format Options:
+    -f, --format FORMAT             Video format code, see "FORMAT SELECTION"
+                                    for more details
+
+  Post-Processing Options:
+    -x, --extract-audio             Convert video files to audio-only files
+                                    (requires ffmpeg and ffprobe)
+    --audio-format FORMAT           Format to convert the audio to when -x is
+                                    used
+    -k, --keep-video                Keep the intermediate video file on disk
+                                    after post-processing
+
+See full documentation at  https://github.com/yt-dlp/yt-dlp#readme
+`
+
+// testCatalog returns the catalog of testHelp
+func testCatalog(t *testing.T) *OptionCatalog {
+	t.Helper()
+	catalog := parseYtDlpHelp(testHelp)
+	if len(catalog.Options) == 0 {
+		t.Fatal("parseYtDlpHelp() found no options")
+	}
+	return catalog
+}
+
+func TestParseYtDlpHelp(t *testing.T) {
+	catalog := testCatalog(t)
+
+	want := []CatalogOption{
+		{Name: "--help", Aliases: []string{"-h"}, Help: "Print this help text and exit"},
+		{Name: "--ignore-errors", Aliases: []string{"-i"}, Help: "Ignore download and postprocessing errors. The download will be considered successful even if the postprocessing fails"},
+		{Name: "--limit-rate", Aliases: []string{"-r"}, Value: "RATE", Help: "Maximum download rate in bytes per second, e.g. 50K or 4.2M"},
+		{Name: "--no-part", Help: "Do not use .part files - write directly into output file"},
+		{Name: "--print", Aliases: []string{"-O"}, Value: "[WHEN:]TEMPLATE", Help: "Field name or output template to print to screen. This option can be used multiple times"},
+		{Name: "--print-to-file", Value: "[WHEN:]TEMPLATE FILE", Help: "Append given template to the file. This option can be used multiple times"},
+		{Name: "--format", Aliases: []string{"-f"}, Value: "FORMAT", Help: `Video format code, see "FORMAT SELECTION" for more details`},
+		{Name: "--extract-audio", Aliases: []string{"-x"}, Help: "Convert video files to audio-only files (requires ffmpeg and ffprobe)"},
+		{Name: "--audio-format", Value: "FORMAT", Help: "Format to convert the audio to when -x is used"},
+		{Name: "--keep-video", Aliases: []string{"-k"}, Help: "Keep the intermediate video file on disk after post-processing"},
+	}
+	if !reflect.DeepEqual(catalog.Options, want) {
+		t.Errorf("parseYtDlpHelp() options:\n%+v\nwant:\n%+v", catalog.Options, want)
+	}
+
+	if option, ok := catalog.Lookup("-f"); !ok || option.Name != "--format" {
+		t.Errorf("Lookup(-f) = %+v, %v, want --format", option, ok)
+	}
+	if option, _ := catalog.Lookup("--print-to-file"); option.Arity() != 2 {
+		t.Errorf("--print-to-file arity = %d, want 2", option.Arity())
+	}
+}
+
+func TestParseOptionUsage(t *testing.T) {
+	tests := []struct {
+		usage  string
+		want   CatalogOption
+		wantOK bool
+	}{
+		{"--no-part", CatalogOption{Name: "--no-part"}, true},
+		{"-f, --format FORMAT", CatalogOption{Name: "--format", Aliases: []string{"-f"}, Value: "FORMAT"}, true},
+		{"--print-to-file [WHEN:]TEMPLATE FILE", CatalogOption{Name: "--print-to-file", Value: "[WHEN:]TEMPLATE FILE"}, true},
+		{"--update-to [CHANNEL]@[TAG]", CatalogOption{Name: "--update-to", Value: "[CHANNEL]@[TAG]"}, true},
+		{"Usage: yt-dlp [OPTIONS]", CatalogOption{}, false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseOptionUsage(test.usage)
+		if ok != test.wantOK || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseOptionUsage(%q) = %+v, %v, want %+v, %v", test.usage, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestCheckFlag(t *testing.T) {
+	catalog := testCatalog(t)
+
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"--format best", ""},
+		{"--format=best", ""},
+		{"-f best", ""},
+		{"-fbest", ""},
+		{"-f -bestaudio", ""},
+		{"--no-part", ""},
+		{"--print-to-file title out.txt", ""},
+		{"--format", "--format expects FORMAT"},
+		{"-f", "-f expects FORMAT"},
+		{"--print-to-file title", "--print-to-file expects [WHEN:]TEMPLATE FILE"},
+		{"--no-part yes", "--no-part doesn't take a value"},
+		{"-f 'a b' c", "-f takes one value, got 2"},
+		{"--fromat best", "Unknown yt-dlp option --fromat, did you mean --format?"},
+		{"--bogus", "Unknown yt-dlp option --bogus"},
+		// Unbalanced quotes are reported by validateFlag
+		{`--format "best`, ""},
+	}
+
+	for _, test := range tests {
+		if got := catalog.CheckFlag(test.flag); got != test.want {
+			t.Errorf("CheckFlag(%q) = %q, want %q", test.flag, got, test.want)
+		}
+	}
+
+	var missing *OptionCatalog
+	if got := missing.CheckFlag("--bogus"); got != "" {
+		t.Errorf("CheckFlag without a catalog = %q, want no warning", got)
+	}
+}
+
+func TestCatalogClosest(t *testing.T) {
+	catalog := testCatalog(t)
+
+	tests := []struct {
+		flag   string
+		want   string
+		wantOK bool
+	}{
+		{"--fromat", "--format", true},
+		{"--limit-rat", "--limit-rate", true},
+		{"--no-prat", "--no-part", true},
+		{"--completely-different", "", false},
+	}
+	for _, test := range tests {
+		got, ok := catalog.Closest(test.flag)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("Closest(%q) = %q, %v, want %q, %v", test.flag, got, ok, test.want, test.wantOK)
+		}
+	}
+
+	var missing *OptionCatalog
+	if got, ok := missing.Closest("--fromat"); ok {
+		t.Errorf("Closest without a catalog = %q", got)
+	}
+}
+
+func TestCatalogComplete(t *testing.T) {
+	catalog := testCatalog(t)
+
+	var got []string
+	for _, option := range catalog.Complete("--pr") {
+		got = append(got, option.Name)
+	}
+	if want := []string{"--print", "--print-to-file"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Complete(--pr) = %q, want %q", got, want)
+	}
+	if got := catalog.Complete(""); got != nil {
+		t.Errorf("Complete(\"\") = %+v, want nothing", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"--format", "--format", 0},
+		{"--fromat", "--format", 2},
+		{"--form", "--format", 2},
+		{"", "-f", 2},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestOptionMergeKey(t *testing.T) {
+	tests := []struct {
+		flag        string
+		want        string
+		wantWithout string // Key without a catalog
+	}{
+		{"--format=best", "--format", "--format"},
+		{"--format best", "--format", "--format"},
+		{"-f best", "--format", "--format"},
+		{"-fbest", "--format", "-fbest"},
+		{"--no-part", "--no-part", "--no-part"},
+		{"--bogus value", "--bogus", "--bogus"},
+		// Repeatable options are only merged with identical ones
+		{"--print title", "--print title", "--print"},
+		{"-O title", "--print title", "-O"},
+		{"--print=title", "--print title", "--print"},
+		{"--print id", "--print id", "--print"},
+	}
+
+	merge := newOptionMerge(testCatalog(t))
+	without := newOptionMerge(nil)
+	for _, test := range tests {
+		option := Option{Flag: test.flag}
+		if got := merge.optionKey(option); got != test.want {
+			t.Errorf("optionKey(%q) = %q, want %q", test.flag, got, test.want)
+		}
+		if got := without.optionKey(option); got != test.wantWithout {
+			t.Errorf("optionKey(%q) without catalog = %q, want %q", test.flag, got, test.wantWithout)
+		}
+	}
+}
+
+func TestOptionMergeOverridesAliases(t *testing.T) {
+	merge := newOptionMerge(testCatalog(t))
+	merge.add(Option{Flag: "--format=best", Source: "Defaults"})
+	merge.add(Option{Flag: "--print title", Source: "Defaults"})
+	merge.add(Option{Flag: "-x", Source: "For Music"})
+	merge.add(Option{Flag: "-f bestaudio", Source: "For Music"})
+	merge.add(Option{Flag: "-O id", Source: "For Music"})
+	merge.add(Option{Flag: "-O title", Source: "For Music"})
+
+	var got []string
+	for _, option := range merge.result() {
+		got = append(got, option.Flag)
+	}
+	// Overridden flags keep their position
+	want := []string{"-f bestaudio", "-O title", "-x", "-O id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged flags = %q, want %q", got, want)
+	}
+
+	conflicts := merge.conflicts()
+	if len(conflicts) != 2 || conflicts[0].Key != "--format" || conflicts[1].Key != "--print title" {
+		t.Fatalf("conflicts = %+v, want --format and --print title", conflicts)
+	}
+	if shadowed := conflicts[0].Shadowed; len(shadowed) != 1 || shadowed[0].Flag != "--format=best" {
+		t.Errorf("--format shadowed = %+v, want --format=best", shadowed)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateField matches a yt-dlp output template field such as %(title)s, %(upload_date>%Y)s,
+// %(uploader|Unknown)s or %(playlist_index)03d, and the %% escape
+var templateField = regexp.MustCompile(`%%|%\(([^)>|]*)(?:>([^)|]*))?(?:\|([^)]*))?\)([-#0+ ]*[0-9]*(?:\.[0-9]+)?)([diouxXeEfFgGcrsqBjlSDU])`)
+
+// strftimeVerbs maps the strftime directives used in output templates to Go layouts
+var strftimeVerbs = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'B': "January", 'b': "Jan",
+	'H': "15", 'M': "04", 'S': "05", 'A': "Monday", 'a': "Mon", 'j': "002",
+}
+
+// defaultOutputTemplate is the template yt-dlp uses when none is given
+const defaultOutputTemplate = "%(title)s [%(id)s].%(ext)s"
+
+// sampleMetadata is used for the filename preview when no video was prefetched
+var sampleMetadata = VideoMetadata{
+	ID:           "dQw4w9WgXcQ",
+	Title:        "Sample Video",
+	Uploader:     "Sample Channel",
+	Channel:      "Sample Channel",
+	Duration:     212,
+	UploadDate:   "20091025",
+	Extractor:    "youtube",
+	ExtractorKey: "Youtube",
+	WebpageURL:   "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	Ext:          "webm",
+}
+
+// templateFields returns the output template fields known from metadata
+func templateFields(info VideoMetadata) map[string]string {
+	ext := info.Ext
+	if ext == "" {
+		ext = "webm"
+	}
+	fields := map[string]string{
+		"id":            info.ID,
+		"title":         info.Title,
+		"fulltitle":     info.Title,
+		"uploader":      info.Uploader,
+		"channel":       info.Channel,
+		"upload_date":   info.UploadDate,
+		"extractor":     info.Extractor,
+		"extractor_key": info.ExtractorKey,
+		"webpage_url":   info.WebpageURL,
+		"ext":           ext,
+	}
+	if info.Duration > 0 {
+		fields["duration"] = strconv.Itoa(int(info.Duration))
+		fields["duration_string"] = formatDuration(info.Duration)
+	}
+	if len(info.UploadDate) == 8 {
+		fields["release_year"] = info.UploadDate[:4]
+	}
+	return fields
+}
+
+// expandOutputTemplate expands an output template the way yt-dlp would for a video with fields.
+// Missing fields become their default or "NA", and slashes in values can't create directories.
+func expandOutputTemplate(template string, fields map[string]string) string {
+	return templateField.ReplaceAllStringFunc(template, func(match string) string {
+		if match == "%%" {
+			return "%"
+		}
+		parts := templateField.FindStringSubmatch(match)
+		name, format, fallback, flags, verb := parts[1], parts[2], parts[3], parts[4], parts[5]
+
+		value, ok := fields[name]
+		if !ok || value == "" {
+			if strings.Contains(match, "|") {
+				return fallback
+			}
+			return "NA"
+		}
+		if format != "" {
+			value = formatTemplateDate(value, format)
+		}
+		return strings.ReplaceAll(formatTemplateValue(value, flags, verb), "/", "⧸")
+	})
+}
+
+// formatTemplateValue applies a printf conversion to a field, treating numeric verbs as numbers
+func formatTemplateValue(value, flags, verb string) string {
+	switch verb {
+	case "d", "i", "o", "u", "x", "X":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if verb == "i" || verb == "u" {
+				verb = "d"
+			}
+			return fmt.Sprintf("%"+flags+verb, number)
+		}
+	case "e", "E", "f", "F", "g", "G":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return fmt.Sprintf("%"+flags+verb, number)
+		}
+	}
+	return fmt.Sprintf("%"+flags+"s", value)
+}
+
+// formatTemplateDate formats a YYYYMMDD date with a strftime format, e.g. %Y-%m
+func formatTemplateDate(value, format string) string {
+	date, err := time.Parse("20060102", value)
+	if err != nil {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if layout, ok := strftimeVerbs[format[i]]; ok {
+			b.WriteString(date.Format(layout))
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
+
+// previewOutputPath returns where a video with info would be saved with an output directory and template
+func previewOutputPath(dir, template string, info VideoMetadata) string {
+	if template == "" {
+		template = defaultOutputTemplate
+	}
+	path := expandOutputTemplate(template, templateFields(info))
+	if filepath.IsAbs(path) || dir == "" {
+		return path
+	}
+	return filepath.Join(expandHome(dir), path)
+}
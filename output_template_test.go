@@ -0,0 +1,116 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExpandOutputTemplate(t *testing.T) {
+	fields := map[string]string{
+		"title":          "AC/DC Live",
+		"id":             "abc",
+		"ext":            "mp4",
+		"upload_date":    "20240305",
+		"playlist_index": "7",
+		"duration":       "212",
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"%(title)s [%(id)s].%(ext)s", "AC⧸DC Live [abc].mp4"},
+		{"%(upload_date>%Y-%m)s/%(title)s.%(ext)s", "2024-03/AC⧸DC Live.mp4"},
+		{"%(playlist_index)03d - %(title)s.%(ext)s", "007 - AC⧸DC Live.mp4"},
+		{"%(uploader)s.%(ext)s", "NA.mp4"},
+		{"%(uploader|Unknown)s.%(ext)s", "Unknown.mp4"},
+		{"%(uploader|)s%(title).4s", "AC⧸D"},
+		{"100%% %(duration)5d", "100%   212"},
+		{"%(upload_date>%d %B %Q)s", "05 March %Q"},
+	}
+	for _, test := range tests {
+		if got := expandOutputTemplate(test.template, fields); got != test.want {
+			t.Errorf("expandOutputTemplate(%q) = %q, want %q", test.template, got, test.want)
+		}
+	}
+}
+
+func TestPreviewOutputPath(t *testing.T) {
+	info := VideoMetadata{ID: "abc", Title: "Video"}
+	tests := []struct {
+		dir      string
+		template string
+		want     string
+	}{
+		{"", "", "Video [abc].webm"},
+		{"/media/videos", "", filepath.Join("/media/videos", "Video [abc].webm")},
+		{"/media/videos", "%(id)s.%(ext)s", filepath.Join("/media/videos", "abc.webm")},
+		{"/media/videos", "/tmp/%(id)s.%(ext)s", "/tmp/abc.webm"},
+	}
+	for _, test := range tests {
+		if got := previewOutputPath(test.dir, test.template, info); got != test.want {
+			t.Errorf("previewOutputPath(%q, %q) = %q, want %q", test.dir, test.template, got, test.want)
+		}
+	}
+}
+
+func TestFlagKey(t *testing.T) {
+	tests := map[string]string{
+		"--format":       "--format",
+		"--format=best":  "--format",
+		"-f":             "--format",
+		"-o":             "--output",
+		"-P":             "--paths",
+		"-x":             "--extract-audio",
+		"-r":             "--limit-rate",
+		"--embed-subs":   "--embed-subs",
+		"-N":             "-N",
+		"--paths=/media": "--paths",
+	}
+	for arg, want := range tests {
+		if got := flagKey(arg); got != want {
+			t.Errorf("flagKey(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+func TestOutputOptionsReplaceFlags(t *testing.T) {
+	pv := PresetsView{Presets: []Preset{
+		{Name: "Raw", Active: true, Options: []Option{
+			{Flag: "-o %(id)s.%(ext)s", Enabled: true},
+			{Flag: "-P /old", Enabled: true},
+			{Flag: "-f best", Enabled: true},
+		}},
+		{Name: "Output", Active: true, OutputDir: "/media/videos", OutputTemplate: "%(title)s.%(ext)s"},
+	}}
+
+	var got []string
+	for _, option := range pv.GetMergedOptions(cliOptions([]string{"--format", "worst"})) {
+		got = append(got, option.Flag+" ← "+option.Source)
+	}
+	sort.Strings(got)
+	want := []string{
+		"--format worst ← " + sourceCommandLine,
+		"--output '%(title)s.%(ext)s' ← Output",
+		"--paths /media/videos ← Output",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMergedOptions() = %q, want %q", got, want)
+	}
+}
+
+func TestPresetOutputOptions(t *testing.T) {
+	preset := Preset{Name: "Music", OutputDir: "/media/music", OutputTemplate: "%(artist)s - %(title)s.%(ext)s"}
+	var args [][]string
+	for _, option := range preset.OutputOptions() {
+		args = append(args, option.Arguments())
+	}
+	want := [][]string{{"--paths", "/media/music"}, {"--output", "%(artist)s - %(title)s.%(ext)s"}}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("OutputOptions() arguments = %q, want %q", args, want)
+	}
+	if options := (Preset{Name: "Empty"}).OutputOptions(); len(options) != 0 {
+		t.Errorf("OutputOptions() without output settings = %v", options)
+	}
+}
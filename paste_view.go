@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var pasteAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+// urlPattern matches http(s) URLs in arbitrary text, stopping at whitespace, quotes and angle brackets
+var urlPattern = regexp.MustCompile("https?://[^\\s<>\"'`]+")
+
+// maxListedURLs limits how many found URLs are listed below the text area
+const maxListedURLs = 10
+
+// PasteView lets the user paste any text and queue every URL found in it
+type PasteView struct {
+	TextArea textarea.Model
+	URLs     []string // URLs extracted from the text, deduplicated
+	Presets  []string // Names of the presets the URLs will be queued with
+}
+
+// CancelPasteMsg is sent when leaving the paste view
+type CancelPasteMsg struct{}
+
+// NewPasteView creates a new PasteView instance
+func NewPasteView() PasteView {
+	textArea := textarea.New()
+	textArea.Placeholder = "Paste chat messages, HTML or a list of links..."
+	textArea.ShowLineNumbers = false
+	textArea.CharLimit = 0 // No limit, pastes can be long
+	textArea.SetWidth(80)
+	textArea.SetHeight(10)
+
+	return PasteView{
+		TextArea: textArea,
+	}
+}
+
+// Reset clears the text area, optionally filling it with already pasted text
+func (pv *PasteView) Reset(text string, presets []string) {
+	pv.Presets = presets
+	pv.TextArea.Reset()
+	pv.TextArea.InsertString(text)
+	pv.TextArea.Focus()
+	pv.URLs = extractURLs(text)
+}
+
+// Update handles input for the PasteView
+func (pv *PasteView) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		pv.TextArea.SetWidth(min(msg.Width-6, 120))
+		pv.TextArea.SetHeight(max(msg.Height-maxListedURLs-12, 5))
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+s":
+			if len(pv.URLs) > 0 {
+				urls := pv.URLs
+				return func() tea.Msg {
+					return EnqueueURLsMsg{URLs: urls}
+				}
+			}
+		case "esc":
+			return func() tea.Msg {
+				return CancelPasteMsg{}
+			}
+		default:
+			pv.TextArea, cmd = pv.TextArea.Update(msg)
+			pv.URLs = extractURLs(pv.TextArea.Value())
+		}
+	}
+
+	return cmd
+}
+
+// View renders the PasteView
+func (pv PasteView) View() string {
+	s := "Paste text with links:\n\n" + pv.TextArea.View() + "\n\n"
+
+	faint := lipgloss.NewStyle().Faint(true)
+	if len(pv.URLs) == 0 {
+		s += faint.Render("No URLs found yet")
+		return pasteAppStyle.Render(s)
+	}
+
+	s += focusedLabelStyle.Render(fmt.Sprintf("Found %d URLs:", len(pv.URLs))) + "\n"
+	for i, url := range pv.URLs {
+		if i == maxListedURLs {
+			s += faint.Render(fmt.Sprintf("...and %d more", len(pv.URLs)-maxListedURLs)) + "\n"
+			break
+		}
+		s += "• " + url + "\n"
+	}
+
+	presets := "no presets"
+	if len(pv.Presets) > 0 {
+		presets = strings.Join(pv.Presets, ", ")
+	}
+	s += "\n" + faint.Render(fmt.Sprintf("Ctrl+S: queue all with %s", presets))
+
+	return pasteAppStyle.Render(s)
+}
+
+// extractURLs returns every distinct http(s) URL found in text, in order of appearance
+func extractURLs(text string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		url := cleanExtractedURL(match)
+		if !isValidURL(url) || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+
+	return urls
+}
+
+// cleanExtractedURL drops punctuation that surrounds URLs in prose and decodes HTML entities
+func cleanExtractedURL(url string) string {
+	url = html.UnescapeString(url)
+
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			// Closing parenthesis of the surrounding sentence, not part of the URL
+			url = url[:len(url)-1]
+		case last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+
+	return url
+}
+
+// containsMultipleURLs reports whether pasted text should be handled by the paste view
+func containsMultipleURLs(text string) bool {
+	return strings.Contains(strings.TrimSpace(text), "\n") || len(extractURLs(text)) > 1
+}
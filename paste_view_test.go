@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "list",
+			text: "https://example.com/1\nhttps://example.com/2\n",
+			want: []string{"https://example.com/1", "https://example.com/2"},
+		},
+		{
+			name: "chat message",
+			text: "check this out: https://youtu.be/abc, and this (https://example.com/b). Nice!",
+			want: []string{"https://youtu.be/abc", "https://example.com/b"},
+		},
+		{
+			name: "parentheses in the URL",
+			text: "see https://en.wikipedia.org/wiki/Go_(programming_language)",
+			want: []string{"https://en.wikipedia.org/wiki/Go_(programming_language)"},
+		},
+		{
+			name: "markdown link",
+			text: "[video](https://example.com/v?id=1&t=2)",
+			want: []string{"https://example.com/v?id=1&t=2"},
+		},
+		{
+			name: "html",
+			text: `<a href="https://example.com/watch?v=1&amp;list=2">one</a> <a href='http://example.com/2'>two</a>`,
+			want: []string{"https://example.com/watch?v=1&list=2", "http://example.com/2"},
+		},
+		{
+			name: "duplicates",
+			text: "https://example.com/1 https://example.com/1. https://example.com/1!",
+			want: []string{"https://example.com/1"},
+		},
+		{
+			name: "no URLs",
+			text: "ftp://example.com and example.com and https://",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		if got := extractURLs(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: extractURLs() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestContainsMultipleURLs(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"https://example.com/1", false},
+		{"  https://example.com/1\n", false},
+		{"https://example.com/1 https://example.com/2", true},
+		{"https://example.com/1\nsome notes", true},
+	}
+
+	for _, test := range tests {
+		if got := containsMultipleURLs(test.text); got != test.want {
+			t.Errorf("containsMultipleURLs(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
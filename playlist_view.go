@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	playlistAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+	playlistTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFDF5")).
+				Background(lipgloss.Color("#25A065")).
+				Padding(0, 1)
+)
+
+// PlaylistView lets the user choose which entries of a playlist or channel to download
+type PlaylistView struct {
+	URL      string
+	Title    string
+	Entries  []PlaylistEntry
+	Selected []bool
+	List     list.Model
+}
+
+// EnqueueEntriesMsg is sent when the selected playlist entries should be queued
+type EnqueueEntriesMsg struct {
+	URL     string
+	Entries []PlaylistEntry
+}
+
+// CancelPlaylistMsg is sent when leaving the playlist without queueing anything
+type CancelPlaylistMsg struct{}
+
+// entryItem wraps PlaylistEntry to implement list.Item interface
+type entryItem struct {
+	entry    PlaylistEntry
+	selected bool
+}
+
+func (i entryItem) Title() string {
+	status := " "
+	if i.selected {
+		status = "✓ "
+	}
+	title := i.entry.Title
+	if title == "" {
+		title = i.entry.EntryURL()
+	}
+	return status + title
+}
+
+func (i entryItem) Description() string {
+	var parts []string
+	uploader := i.entry.Uploader
+	if uploader == "" {
+		uploader = i.entry.Channel
+	}
+	if uploader != "" {
+		parts = append(parts, uploader)
+	}
+	if i.entry.Duration > 0 {
+		parts = append(parts, formatDuration(i.entry.Duration))
+	}
+	if len(parts) == 0 {
+		return i.entry.EntryURL()
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (i entryItem) FilterValue() string {
+	return i.entry.Title
+}
+
+// NewPlaylistView creates a new PlaylistView instance
+func NewPlaylistView() PlaylistView {
+	entriesList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	entriesList.SetShowTitle(false) // Hide title
+	entriesList.SetShowHelp(false)  // We'll handle help separately
+
+	return PlaylistView{
+		List: entriesList,
+	}
+}
+
+// SetMetadata loads the entries of a playlist, all of them selected
+func (pv *PlaylistView) SetMetadata(url string, info *VideoMetadata) {
+	pv.URL = url
+	pv.Title = info.Title
+	pv.Entries = pv.Entries[:0]
+	for _, entry := range info.Entries {
+		// Skip entries yt-dlp couldn't resolve to anything downloadable
+		if entry.EntryURL() != "" {
+			pv.Entries = append(pv.Entries, entry)
+		}
+	}
+
+	pv.Selected = make([]bool, len(pv.Entries))
+	for i := range pv.Selected {
+		pv.Selected[i] = true
+	}
+
+	pv.updateListItems()
+	pv.List.Select(0)
+}
+
+// updateListItems synchronizes the list items with entries and selection
+func (pv *PlaylistView) updateListItems() {
+	items := make([]list.Item, len(pv.Entries))
+	for i := range pv.Entries {
+		items[i] = entryItem{entry: pv.Entries[i], selected: pv.Selected[i]}
+	}
+	pv.List.SetItems(items)
+}
+
+// Update handles input for the PlaylistView
+func (pv *PlaylistView) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := playlistAppStyle.GetFrameSize()
+		header := 3 // Title and selection summary
+		pv.List.SetSize(msg.Width-h, msg.Height-v-header)
+	case tea.KeyMsg:
+		// Let the list handle filtering input on its own
+		if pv.List.FilterState() == list.Filtering {
+			var cmd tea.Cmd
+			pv.List, cmd = pv.List.Update(msg)
+			return cmd
+		}
+
+		switch msg.String() {
+		case " ":
+			// Toggle entry selected/unselected, the list index is off while filtering
+			selectedIndex := pv.List.GlobalIndex()
+			if selectedIndex < len(pv.Entries) {
+				pv.Selected[selectedIndex] = !pv.Selected[selectedIndex]
+				pv.updateListItems()
+			}
+		case "a", "A":
+			// Select all entries, or none if all are selected already
+			all := pv.SelectedCount() < len(pv.Entries)
+			for i := range pv.Selected {
+				pv.Selected[i] = all
+			}
+			pv.updateListItems()
+		case "enter":
+			entries := pv.SelectedEntries()
+			if len(entries) > 0 {
+				url := pv.URL
+				return func() tea.Msg {
+					return EnqueueEntriesMsg{URL: url, Entries: entries}
+				}
+			}
+		case "esc":
+			return func() tea.Msg {
+				return CancelPlaylistMsg{}
+			}
+		default:
+			var cmd tea.Cmd
+			pv.List, cmd = pv.List.Update(msg)
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// SelectedEntries returns the entries that are checked
+func (pv PlaylistView) SelectedEntries() []PlaylistEntry {
+	var entries []PlaylistEntry
+	for i, entry := range pv.Entries {
+		if pv.Selected[i] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// SelectedCount returns the number of checked entries
+func (pv PlaylistView) SelectedCount() int {
+	count := 0
+	for _, selected := range pv.Selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+// View renders the PlaylistView
+func (pv PlaylistView) View() string {
+	s := playlistTitleStyle.Render("Playlist: "+pv.Title) + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("%d of %d selected", pv.SelectedCount(), len(pv.Entries))) + "\n\n"
+
+	if len(pv.Entries) == 0 {
+		s += "This playlist has no entries"
+	} else {
+		s += pv.List.View()
+	}
+
+	return playlistAppStyle.Render(s)
+}
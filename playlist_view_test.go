@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newTestPlaylistView() PlaylistView {
+	pv := NewPlaylistView()
+	pv.List.SetSize(80, 40)
+	pv.SetMetadata("https://example.com/playlist", &VideoMetadata{
+		Type:  "playlist",
+		Title: "Mix",
+		Entries: []PlaylistEntry{
+			{Title: "Intro", URL: "https://example.com/1"},
+			{Title: "Unavailable"},
+			{Title: "Main theme", URL: "https://example.com/2"},
+			{Title: "Outro", WebpageURL: "https://example.com/3"},
+		},
+	})
+	return pv
+}
+
+func TestPlaylistViewToggle(t *testing.T) {
+	pv := newTestPlaylistView()
+	if len(pv.Entries) != 3 || pv.SelectedCount() != 3 {
+		t.Fatalf("got %d entries, %d selected, want 3 and 3", len(pv.Entries), pv.SelectedCount())
+	}
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	pv.List.Select(1)
+	pv.Update(space)
+	if entries := pv.SelectedEntries(); len(entries) != 2 || entries[1].Title != "Outro" {
+		t.Errorf("SelectedEntries() = %+v, want Intro and Outro", entries)
+	}
+
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if pv.SelectedCount() != 3 {
+		t.Errorf("a selected %d entries, want all 3", pv.SelectedCount())
+	}
+	pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if pv.SelectedCount() != 0 {
+		t.Errorf("a with everything selected left %d entries, want 0", pv.SelectedCount())
+	}
+}
+
+func TestPlaylistViewToggleWhileFiltered(t *testing.T) {
+	pv := newTestPlaylistView()
+
+	// The only match sits at index 0 of the filtered list but 2 of all entries
+	pv.List.SetFilterText("Outro")
+	pv.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+
+	want := []bool{true, true, false}
+	for i, selected := range pv.Selected {
+		if selected != want[i] {
+			t.Errorf("Selected = %v, want %v", pv.Selected, want)
+			break
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -36,7 +37,8 @@ var (
 
 // optionItem wraps Option to implement list.Item interface
 type optionItem struct {
-	option *Option
+	option  *Option
+	warning string // Why yt-dlp would reject the flag
 }
 
 func (i optionItem) Title() string {
@@ -48,6 +50,9 @@ func (i optionItem) Title() string {
 }
 
 func (i optionItem) Description() string {
+	if i.warning != "" {
+		return "⚠ " + i.warning
+	}
 	if i.option.Comment == "" {
 		return "No description"
 	}
@@ -77,6 +82,17 @@ func NewPresetView() PresetView {
 	presetNameInput.CharLimit = 50
 	presetNameInput.Width = 100
 
+	// Output settings inputs
+	outputDirInput := textinput.New()
+	outputDirInput.Placeholder = "~/Videos (empty: current directory)"
+	outputDirInput.CharLimit = 1024
+	outputDirInput.Width = 100
+
+	outputTemplateInput := textinput.New()
+	outputTemplateInput.Placeholder = defaultOutputTemplate
+	outputTemplateInput.CharLimit = 1024
+	outputTemplateInput.Width = 100
+
 	// Create options list
 	optionsList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	optionsList.SetShowTitle(false) // Hide title
@@ -89,6 +105,9 @@ func NewPresetView() PresetView {
 		CommentInput:    commentInput,
 		PresetNameInput: presetNameInput,
 		InputFocus:      0,
+
+		OutputDirInput:      outputDirInput,
+		OutputTemplateInput: outputTemplateInput,
 	}
 }
 
@@ -105,9 +124,63 @@ func (pv *PresetView) SetPreset(preset *Preset) {
 	pv.FlagInput.Blur()
 	pv.CommentInput.Blur()
 	pv.PresetNameInput.Blur()
+	pv.closeOutputEditor()
 	pv.updateOptionsList()
 }
 
+// openOutputEditor starts editing the output settings of the preset
+func (pv *PresetView) openOutputEditor() {
+	pv.OutputDirInput.SetValue(pv.Preset.OutputDir)
+	pv.OutputTemplateInput.SetValue(pv.Preset.OutputTemplate)
+	pv.OutputDirInput.CursorEnd()
+	pv.OutputTemplateInput.CursorEnd()
+	pv.EditingOutput = true
+	pv.focusOutput(0)
+}
+
+// closeOutputEditor leaves the output settings editor without saving
+func (pv *PresetView) closeOutputEditor() {
+	pv.EditingOutput = false
+	pv.OutputDirInput.Blur()
+	pv.OutputTemplateInput.Blur()
+}
+
+// focusOutput focuses the directory (0) or template (1) input
+func (pv *PresetView) focusOutput(focus int) {
+	pv.OutputFocus = focus
+	if focus == 0 {
+		pv.OutputDirInput.Focus()
+		pv.OutputTemplateInput.Blur()
+	} else {
+		pv.OutputDirInput.Blur()
+		pv.OutputTemplateInput.Focus()
+	}
+}
+
+// updateOutputEditor handles input while the output settings are edited
+func (pv *PresetView) updateOutputEditor(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "enter":
+		pv.Preset.OutputDir = strings.TrimSpace(pv.OutputDirInput.Value())
+		pv.Preset.OutputTemplate = strings.TrimSpace(pv.OutputTemplateInput.Value())
+		pv.closeOutputEditor()
+	case "esc":
+		pv.closeOutputEditor()
+	case "tab", "shift+tab", "up", "down":
+		pv.focusOutput(1 - pv.OutputFocus)
+	default:
+		if pv.OutputFocus == 0 {
+			pv.OutputDirInput, cmd = pv.OutputDirInput.Update(msg)
+		} else {
+			pv.OutputTemplateInput, cmd = pv.OutputTemplateInput.Update(msg)
+		}
+	}
+
+	return cmd
+}
+
 // updateOptionsList synchronizes the options list with the current preset
 func (pv *PresetView) updateOptionsList() {
 	if pv.Preset == nil {
@@ -117,7 +190,10 @@ func (pv *PresetView) updateOptionsList() {
 
 	items := make([]list.Item, len(pv.Preset.Options))
 	for i := range pv.Preset.Options {
-		items[i] = optionItem{option: &pv.Preset.Options[i]}
+		items[i] = optionItem{
+			option:  &pv.Preset.Options[i],
+			warning: pv.Catalog.CheckFlag(pv.Preset.Options[i].Flag),
+		}
 	}
 	pv.OptionsList.SetItems(items)
 }
@@ -148,6 +224,9 @@ func (pv *PresetView) Update(msg tea.Msg) (tea.Cmd, *Preset) {
 		}
 		pv.OptionsList.SetSize(msg.Width-h, availableHeight)
 	case tea.KeyMsg:
+		if pv.EditingOutput && pv.Preset != nil {
+			return pv.updateOutputEditor(msg), newPreset
+		}
 		if pv.InputFocus == 5 {
 			// New preset name input mode
 			switch msg.String() {
@@ -191,6 +270,11 @@ func (pv *PresetView) Update(msg tea.Msg) (tea.Cmd, *Preset) {
 						}
 					}
 				}
+			case "o", "O":
+				// Edit where downloads are saved
+				if pv.Preset != nil {
+					pv.openOutputEditor()
+				}
 			case "r", "R":
 				// Reset current preset to defaults (only when in options list)
 				if pv.InputFocus == 2 && pv.Preset != nil {
@@ -328,6 +412,10 @@ func (pv PresetView) View() string {
 		return presetAppStyle.Render("No preset selected")
 	}
 
+	if pv.EditingOutput {
+		return presetAppStyle.Render(pv.viewOutputEditor())
+	}
+
 	// Build content
 	content := pv.buildPresetContent()
 
@@ -371,6 +459,53 @@ func (pv PresetView) buildPresetContent() string {
 	}
 	s += addButton
 
+	// Where downloads end up
+	faint := lipgloss.NewStyle().Faint(true)
+	if pv.Preset.OutputDir != "" || pv.Preset.OutputTemplate != "" {
+		s += "\n" + faint.Render("Output: "+pv.previewPath()+" (O: edit)")
+	} else {
+		s += "\n" + faint.Render("Output: yt-dlp default (O: edit)")
+	}
+
+	return s
+}
+
+// previewPath expands the output settings being edited, or the saved ones, for the sample video
+func (pv PresetView) previewPath() string {
+	dir, template := pv.Preset.OutputDir, pv.Preset.OutputTemplate
+	if pv.EditingOutput {
+		dir, template = strings.TrimSpace(pv.OutputDirInput.Value()), strings.TrimSpace(pv.OutputTemplateInput.Value())
+	}
+
+	info := sampleMetadata
+	if pv.Sample != nil && !pv.Sample.IsPlaylist() {
+		info = *pv.Sample
+	}
+	return previewOutputPath(dir, template, info)
+}
+
+// viewOutputEditor renders the output directory and template inputs with a live filename preview
+func (pv PresetView) viewOutputEditor() string {
+	dirLabel, templateLabel := "Directory:", "Filename template:"
+	if pv.OutputFocus == 0 {
+		dirLabel = focusedLabelStyle.Render(dirLabel)
+	} else {
+		templateLabel = focusedLabelStyle.Render(templateLabel)
+	}
+
+	s := fmt.Sprintf("Output of \"%s\":\n\n", pv.Preset.Name)
+	s += dirLabel + "\n" + pv.OutputDirInput.View() + "\n\n"
+	s += templateLabel + "\n" + pv.OutputTemplateInput.View() + "\n\n"
+
+	faint := lipgloss.NewStyle().Faint(true)
+	s += focusedLabelStyle.Render("Preview:") + " " + pv.previewPath() + "\n"
+	if pv.Sample != nil && !pv.Sample.IsPlaylist() {
+		s += faint.Render("Using the info of "+pv.Sample.Title) + "\n"
+	} else {
+		s += faint.Render("Using sample data, type a URL first to preview a real video") + "\n"
+	}
+	s += "\n" + faint.Render("Fields: %(title)s %(id)s %(ext)s %(uploader)s %(upload_date>%Y-%m-%d)s %(field|default)s")
+
 	return s
 }
 
@@ -86,6 +86,13 @@ func (pv *PresetsView) Update(msg tea.Msg) tea.Cmd {
 		h, v := presetsAppStyle.GetFrameSize()
 		pv.List.SetSize(msg.Width-h, msg.Height-v)
 	case tea.KeyMsg:
+		// Let the list handle filtering input on its own
+		if pv.List.FilterState() == list.Filtering {
+			var cmd tea.Cmd
+			pv.List, cmd = pv.List.Update(msg)
+			return cmd
+		}
+
 		switch msg.String() {
 		case " ":
 			// Toggle preset active/inactive
@@ -106,6 +113,12 @@ func (pv *PresetsView) Update(msg tea.Msg) tea.Cmd {
 					pv.List.Select(len(pv.Presets) - 1)
 				}
 			}
+		case "K", "shift+up":
+			// Move the preset up, giving it a lower priority
+			pv.movePreset(-1)
+		case "J", "shift+down":
+			// Move the preset down, giving it a higher priority
+			pv.movePreset(1)
 		case "r", "R":
 			// Reset to default presets
 			pv.Presets = GetDefaultPresets()
@@ -122,6 +135,22 @@ func (pv *PresetsView) Update(msg tea.Msg) tea.Cmd {
 	return nil
 }
 
+// movePreset swaps the selected preset with its neighbour by delta and keeps it selected.
+// Presets aren't moved while the list is filtered, as their neighbours may be hidden.
+func (pv *PresetsView) movePreset(delta int) {
+	if pv.List.FilterState() != list.Unfiltered {
+		return
+	}
+	index := pv.List.GlobalIndex()
+	target := index + delta
+	if index < 0 || index >= len(pv.Presets) || target < 0 || target >= len(pv.Presets) {
+		return
+	}
+	pv.Presets[index], pv.Presets[target] = pv.Presets[target], pv.Presets[index]
+	pv.updateListItems()
+	pv.List.Select(target)
+}
+
 // updateListItems synchronizes the list items with the current presets
 func (pv *PresetsView) updateListItems() {
 	items := make([]list.Item, len(pv.Presets))
@@ -139,109 +168,137 @@ func (pv PresetsView) View() string {
 // getPresetsHelp returns help text for presets view
 func getPresetsHelp() string {
 	help := lipgloss.NewStyle().Faint(true)
-	return help.Render("N: new preset • Enter: edit • Space: toggle • D: delete • R: reset all • Esc: back • ?: help")
+	return help.Render("N: new preset • Enter: edit • Space: toggle • K/J: move • C: conflicts • D: delete • R: reset all • Esc: back • ?: help")
 }
 
-// GetActiveOptions returns all enabled options from active presets, handling conflicts
-func (pv PresetsView) GetActiveOptions() []Option {
-	// Use a map to handle conflicts between presets
-	flagMap := make(map[string]Option)
-
+// mergePresets merges the enabled options of active presets in list order,
+// so presets further down override the ones above them
+func (pv PresetsView) mergePresets() *optionMerge {
+	merge := newOptionMerge(pv.Catalog)
 	for _, preset := range pv.Presets {
-		if preset.Active {
-			for _, option := range preset.Options {
-				if option.Enabled {
-					flagParts := strings.Fields(option.Flag)
-					if len(flagParts) > 0 {
-						key := flagParts[0] // e.g., "--format" from "--format=best"
-						// Later presets override earlier ones
-						flagMap[key] = option
-					}
-				}
+		if !preset.Active {
+			continue
+		}
+		for _, option := range preset.Options {
+			if option.Enabled {
+				option.Source = preset.Name
+				merge.add(option)
 			}
 		}
+		// Output settings override raw -o and -P flags of the preset
+		for _, option := range preset.OutputOptions() {
+			merge.add(option)
+		}
 	}
+	return merge
+}
 
-	// Convert map back to slice
-	var activeOptions []Option
-	for _, option := range flagMap {
-		activeOptions = append(activeOptions, option)
-	}
-
-	return activeOptions
+// GetActiveOptions returns all enabled options from active presets, handling conflicts
+func (pv PresetsView) GetActiveOptions() []Option {
+	return pv.mergePresets().result()
 }
 
-// GetMergedOptions returns options merged with CLI arguments, handling conflicts
-func (pv PresetsView) GetMergedOptions(cliArgs []string) []Option {
-	// Start with active options from presets
-	presetOptions := pv.GetActiveOptions()
+// Conflicts returns the flags set by more than one active preset
+func (pv PresetsView) Conflicts() []OptionConflict {
+	return pv.mergePresets().conflicts()
+}
 
-	// Create a map to track flags by their key (first word of flag)
-	flagMap := make(map[string]Option)
+// GetMergedOptions returns options merged with options parsed from CLI arguments, handling conflicts
+func (pv PresetsView) GetMergedOptions(cliOptions []Option) []Option {
+	// Start with active options from presets, CLI arguments override them
+	merge := pv.mergePresets()
+	for _, option := range cliOptions {
+		merge.add(option)
+	}
+	mergedOptions := merge.result()
 
-	// Add preset options to map
-	for _, option := range presetOptions {
-		flagParts := strings.Fields(option.Flag)
-		if len(flagParts) > 0 {
-			key := flagParts[0] // e.g., "--format" from "--format=best"
-			flagMap[key] = option
+	// Log merged options for debugging
+	if len(cliOptions) > 0 {
+		var flagStrings []string
+		for _, option := range mergedOptions {
+			flagStrings = append(flagStrings, option.Flag)
 		}
+		logToFile("Merged flags: " + strings.Join(flagStrings, " "))
 	}
 
-	// Process CLI arguments and add/override flags
-	for i := 0; i < len(cliArgs); i++ {
-		arg := cliArgs[i]
+	return mergedOptions
+}
 
-		// Skip if it doesn't look like a flag
-		if !strings.HasPrefix(arg, "-") {
-			continue
+// ActivePresetNames returns the names of all active presets
+func (pv PresetsView) ActivePresetNames() []string {
+	var names []string
+	for _, preset := range pv.Presets {
+		if preset.Active {
+			names = append(names, preset.Name)
 		}
+	}
+	return names
+}
 
-		// Handle different flag formats
-		var flagKey, fullFlag string
-		if strings.Contains(arg, "=") {
-			// Format: --flag=value
-			parts := strings.SplitN(arg, "=", 2)
-			flagKey = parts[0]
-			fullFlag = arg
-		} else {
-			// Format: --flag value (if next arg doesn't start with -)
-			flagKey = arg
-			if i+1 < len(cliArgs) && !strings.HasPrefix(cliArgs[i+1], "-") {
-				fullFlag = arg + " " + cliArgs[i+1]
-				i++ // Skip next argument as it's the value
-			} else {
-				fullFlag = arg
-			}
+// ActiveHooks returns the hooks of the active presets followed by the global hooks
+func (pv PresetsView) ActiveHooks() []JobHook {
+	var hooks []JobHook
+	for _, preset := range pv.Presets {
+		if !preset.Active {
+			continue
 		}
-
-		// Add or override in map
-		flagMap[flagKey] = Option{
-			Flag:    fullFlag,
-			Comment: "From CLI arguments",
-			Enabled: true,
+		for _, hook := range preset.Hooks {
+			hooks = append(hooks, JobHook{Hook: hook, Preset: preset.Name})
 		}
 	}
-
-	// Convert map back to slice
-	var mergedOptions []Option
-	for _, option := range flagMap {
-		mergedOptions = append(mergedOptions, option)
+	for _, hook := range appSettings.Hooks {
+		hooks = append(hooks, JobHook{Hook: hook})
 	}
+	return hooks
+}
 
-	// Log merged options for debugging
-	if len(cliArgs) > 0 {
-		var flagStrings []string
-		for _, option := range mergedOptions {
-			flagStrings = append(flagStrings, option.Flag)
+// ArchivePath returns the download archive new jobs are recorded in:
+// the archive of the first active preset that has one, otherwise the global archive.
+// It returns an empty string if downloads aren't archived.
+func (pv PresetsView) ArchivePath() string {
+	for _, preset := range pv.Presets {
+		if preset.Active && preset.Archive != "" {
+			return preset.Archive
 		}
-		logToFile("Merged flags: " + strings.Join(flagStrings, " "))
+	}
+	if !appSettings.Archive {
+		return ""
 	}
 
-	return mergedOptions
+	path, err := getArchiveFilePath()
+	if err != nil {
+		logToFile("Failed to locate download archive: " + err.Error())
+		return ""
+	}
+	return path
 }
 
 // GetTitle returns the appropriate title for the presets view
 func (pv PresetsView) GetTitle() string {
 	return "Presets"
 }
+
+// OutputOptions returns the --paths and --output options for the preset's output settings
+func (p Preset) OutputOptions() []Option {
+	var options []Option
+	if p.OutputDir != "" {
+		dir := expandHome(p.OutputDir)
+		options = append(options, Option{
+			Flag:    "--paths " + shellQuote(dir),
+			Comment: "Output directory",
+			Enabled: true,
+			Source:  p.Name,
+			Args:    []string{"--paths", dir},
+		})
+	}
+	if p.OutputTemplate != "" {
+		options = append(options, Option{
+			Flag:    "--output " + shellQuote(p.OutputTemplate),
+			Comment: "Output filename template",
+			Enabled: true,
+			Source:  p.Name,
+			Args:    []string{"--output", p.OutputTemplate},
+		})
+	}
+	return options
+}
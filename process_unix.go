@@ -0,0 +1,50 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup makes cmd the leader of a new process group so it can be signalled with its children
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// hookCommand runs a hook command line with sh in a new process group
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	setProcessGroup(cmd)
+	return cmd
+}
+
+// quoteHookValue quotes a variable substituted into a hook command line
+func quoteHookValue(value string) string {
+	return shellQuote(value)
+}
+
+// killProcessGroup kills the process group of cmd, including ffmpeg and other children
+func killProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGKILL)
+}
+
+// pauseProcessGroup stops the process group of cmd
+func pauseProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGSTOP)
+}
+
+// resumeProcessGroup continues a stopped process group of cmd
+func resumeProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGCONT)
+}
+
+// signalProcessGroup sends sig to every process in the group of cmd
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd == nil || cmd.Process == nil {
+		return errNoProcess
+	}
+	// A negative pid addresses the whole process group
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// errPauseUnsupported is returned when pausing is requested on Windows
+var errPauseUnsupported = errors.New("pausing downloads is not supported on Windows")
+
+// setProcessGroup starts cmd in a new process group so console signals don't reach it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// hookCommand runs a hook command line with cmd.exe in a new process group
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd.exe")
+	// cmd.exe parses its own command line, so it's passed on unescaped
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine:       `cmd.exe /S /C "` + command + `"`,
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+	return cmd
+}
+
+// quoteHookValue quotes a variable substituted into a hook command line.
+// cmd.exe expands %VAR% even inside quotes, so every % is escaped outside of them.
+func quoteHookValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, `""`)
+	return `"` + strings.ReplaceAll(value, "%", `"^%"`) + `"`
+}
+
+// killProcessGroup kills cmd together with its child processes
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd == nil || cmd.Process == nil {
+		return errNoProcess
+	}
+	// taskkill /T also terminates children such as ffmpeg
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// pauseProcessGroup is not available on Windows
+func pauseProcessGroup(cmd *exec.Cmd) error {
+	return errPauseUnsupported
+}
+
+// resumeProcessGroup is not available on Windows
+func resumeProcessGroup(cmd *exec.Cmd) error {
+	return errPauseUnsupported
+}
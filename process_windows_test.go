@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import "testing"
+
+func TestQuoteHookValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"video.mp4", `"video.mp4"`},
+		{`say "hi"`, `"say ""hi"""`},
+		{"100% %PATH%", `"100"^%" "^%"PATH"^%""`},
+	}
+	for _, test := range tests {
+		if got := quoteHookValue(test.value); got != test.want {
+			t.Errorf("quoteHookValue(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"time"
+)
+
+// JobState represents where a job is in the download queue
+type JobState int
+
+const (
+	JobQueued JobState = iota
+	JobRunning
+	JobPaused
+	JobRetrying
+	JobDone
+	JobFailed
+	JobCanceled
+)
+
+// String returns a human readable job state
+func (s JobState) String() string {
+	switch s {
+	case JobQueued:
+		return "queued"
+	case JobRunning:
+		return "running"
+	case JobPaused:
+		return "paused"
+	case JobRetrying:
+		return "retrying"
+	case JobDone:
+		return "done"
+	case JobFailed:
+		return "failed"
+	case JobCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// Job is a single download in the queue
+type Job struct {
+	ID           int
+	URL          string
+	Title        string   // Title known when queueing, e.g. from a playlist entry
+	Options      []Option // Snapshot of merged options taken when the job was queued
+	Presets      []string // Names of the presets active when the job was queued
+	Archive      string   // Download archive the job is recorded in, if any
+	State        JobState
+	Progress     DownloadProgress
+	Attempts     int         // Number of times yt-dlp was started
+	Failure      FailureKind // Why the last attempt failed
+	RetryAt      time.Time   // When a retrying job is started again
+	RateLimit    float64     // Rate limit the job was started with in bytes per second, +Inf for none
+	StartAt      time.Time   // Don't start before this time
+	Window       *TimeWindow // Only run inside this daily window
+	Hooks        []JobHook   // Commands run after a successful download
+	HookErrors   []string    // Hooks that failed or timed out, the download itself succeeded
+	HooksRunning bool        // Hooks of the finished download are running
+	cmd          *exec.Cmd   // Running yt-dlp process
+	canceling    bool        // Process was killed on request
+	restarting   bool        // Process was killed to be started again, e.g. with a new rate limit
+	partial      bool        // A partial download is left to continue
+}
+
+// Queue runs queued jobs on a limited number of workers
+type Queue struct {
+	Jobs       []*Job
+	Workers    int
+	Retries    int           // Retries allowed after a transient failure
+	RetryDelay time.Duration // Backoff before the first retry
+	RateLimit  float64       // Bandwidth shared by all running jobs in bytes per second, 0 for none
+	// DomainLimits caps running jobs per domain, including its subdomains
+	DomainLimits map[string]int
+	StatePath    string // File unfinished jobs are saved to, empty to keep them in memory only
+	nextID       int
+	rebalanceAt  time.Time        // When running jobs get their new share of the rate limit, zero if it's up to date
+	events       chan DownloadMsg // Progress of every running job
+}
+
+// NewQueue creates an empty queue running at most workers jobs at once
+func NewQueue(workers int) *Queue {
+	settings := GetDefaultSettings()
+	return &Queue{
+		Workers:    max(workers, 1),
+		Retries:    settings.Retries,
+		RetryDelay: time.Duration(settings.RetryDelay) * time.Second,
+		nextID:     1,
+		events:     make(chan DownloadMsg),
+	}
+}
+
+// Enqueue adds a new job for url with a snapshot of options and the presets they came from
+func (q *Queue) Enqueue(url string, options []Option, presets []string) *Job {
+	snapshot := make([]Option, len(options))
+	copy(snapshot, options)
+
+	job := &Job{
+		ID:       q.nextID,
+		URL:      url,
+		Options:  snapshot,
+		Presets:  presets,
+		State:    JobQueued,
+		Progress: DownloadProgress{URL: url, State: DownloadIdle},
+	}
+	q.nextID++
+	q.Jobs = append(q.Jobs, job)
+
+	logToFile(fmt.Sprintf("Queued job %d: %s", job.ID, url))
+	q.Save()
+	return job
+}
+
+// Schedule starts ready jobs, and retries that are due, until all workers are busy
+// or their domain has reached its limit.
+// Running jobs whose schedule no longer allows them to run are stopped and continue later.
+func (q *Queue) Schedule() {
+	now := time.Now()
+	for _, job := range q.Jobs {
+		// Post-processing is left to finish
+		if job.State == JobRunning && !job.Ready(now) && !job.restarting && job.Progress.Percent() < 1 {
+			q.hold(job)
+		}
+	}
+
+	for _, job := range q.Jobs {
+		if q.Active() >= q.Workers {
+			break
+		}
+		if (job.State == JobQueued && job.Ready(now)) || (job.State == JobRetrying && !now.Before(job.RetryAt)) {
+			if !q.domainAvailable(job.URL) {
+				continue
+			}
+			if !job.partial {
+				job.Attempts++
+			}
+			q.start(job)
+			// The running jobs have to share the rate limit with one more
+			q.requestRebalance()
+		}
+	}
+}
+
+// hold stops a running job so it's started again later, continuing where it left off
+func (q *Queue) hold(job *Job) {
+	logToFile(fmt.Sprintf("Holding job %d until its schedule allows it to run", job.ID))
+	job.restarting = true
+	if err := killProcessGroup(job.cmd); err != nil {
+		job.restarting = false
+		logToFile(fmt.Sprintf("Failed to hold job %d: %s", job.ID, err.Error()))
+	}
+}
+
+// start launches yt-dlp for a single job, continuing its partial download if there is one
+func (q *Queue) start(job *Job) {
+	options := job.Options
+	if job.partial {
+		options = append(options[:len(options):len(options)], Option{Flag: "--continue", Enabled: true})
+		job.partial = false
+	}
+	share := q.rateShare(q.Running() + 1)
+	options = applyRateLimit(options, share)
+	job.RateLimit = math.Min(share, optionsRate(job.Options))
+
+	cmd, err := ExecuteYtDlpCmd(job.ID, job.URL, options, q.events)
+	if err != nil {
+		logToFile(fmt.Sprintf("Failed to start job %d: %s", job.ID, err.Error()))
+		job.State = JobFailed
+		job.Progress.State = DownloadError
+		job.Progress.Error = err.Error()
+		q.Save()
+		return
+	}
+
+	job.cmd = cmd
+	job.State = JobRunning
+	job.Progress.State = DownloadRunning
+	q.Save()
+}
+
+// Handle applies a progress message to its job and returns the job, or nil if it's unknown
+func (q *Queue) Handle(msg DownloadMsg) *Job {
+	job := q.Job(msg.JobID)
+	if job == nil {
+		return nil
+	}
+	// Running jobs report progress all the time, so pending rebalances are noticed here
+	q.rebalanceIfSettled(time.Now())
+
+	destinations := len(job.Progress.Destinations)
+	output := job.Progress.Output
+	if msg.Line != "" {
+		output = append(output, msg.Line)
+		if len(output) > maxOutputLines {
+			output = output[len(output)-maxOutputLines:]
+		}
+	}
+	job.Progress = msg.Progress
+	job.Progress.Output = output
+
+	if !msg.Done {
+		// New files have to be saved so a restart can continue or clean them up
+		if len(job.Progress.Destinations) != destinations {
+			q.Save()
+		}
+		return job
+	}
+
+	job.cmd = nil
+	if job.restarting && !job.canceling {
+		// Start again right away unless the job's window has closed
+		job.restarting = false
+		job.State = JobQueued
+		job.partial = true
+		if job.Ready(time.Now()) {
+			q.start(job)
+		} else {
+			q.requestRebalance()
+		}
+		return job
+	}
+	// The other jobs can use the bandwidth of this one
+	q.requestRebalance()
+	if job.canceling {
+		job.canceling = false
+		job.restarting = false
+		job.partial = false
+		job.State = JobCanceled
+		job.Progress.State = DownloadIdle
+		cleanupPartialFiles(job.Progress.Destinations)
+	} else if msg.Error != nil {
+		q.fail(job, msg.Error)
+	} else {
+		job.State = JobDone
+		job.Progress.State = DownloadCompleted
+		job.Progress.Percentage = "100%"
+	}
+	q.Save()
+	return job
+}
+
+// fail classifies a failed attempt and either schedules a retry with backoff or gives up
+func (q *Queue) fail(job *Job, err error) {
+	job.Failure = classifyFailure(job.Progress.Errors)
+	// yt-dlp's own error explains more than its exit status
+	message := err.Error()
+	if job.Progress.Error != "" {
+		message = job.Progress.Error
+	}
+
+	if job.Failure.Transient() && job.Attempts <= q.Retries {
+		delay := retryDelay(q.RetryDelay, job.Attempts)
+		job.State = JobRetrying
+		job.RetryAt = time.Now().Add(delay)
+		job.Progress.State = DownloadIdle
+		job.Progress.Error = fmt.Sprintf("%s, retry %d of %d in %s: %s", job.Failure, job.Attempts, q.Retries, delay, message)
+		logToFile(fmt.Sprintf("Job %d failed (%s), retrying in %s", job.ID, job.Failure, delay))
+		return
+	}
+
+	job.State = JobFailed
+	job.Progress.State = DownloadError
+	job.Progress.Error = job.Failure.String() + ": " + message
+	if job.Attempts > 1 {
+		job.Progress.Error += fmt.Sprintf(" (after %d attempts)", job.Attempts)
+	}
+}
+
+// Cancel stops a job, killing its yt-dlp process if it's already running
+func (q *Queue) Cancel(job *Job) error {
+	switch job.State {
+	case JobQueued, JobRetrying:
+		job.State = JobCanceled
+		q.Save()
+	case JobRunning, JobPaused:
+		// The job is marked canceled once its process exits
+		job.canceling = true
+		if err := killProcessGroup(job.cmd); err != nil {
+			job.canceling = false
+			return err
+		}
+	}
+	return nil
+}
+
+// Pause suspends the yt-dlp process of a running job
+func (q *Queue) Pause(job *Job) error {
+	if job.State != JobRunning {
+		return nil
+	}
+	if err := pauseProcessGroup(job.cmd); err != nil {
+		return err
+	}
+	job.State = JobPaused
+	q.Save()
+	// Running jobs can use the bandwidth of the paused one
+	q.requestRebalance()
+	return nil
+}
+
+// Resume continues the yt-dlp process of a paused job
+func (q *Queue) Resume(job *Job) error {
+	if job.State != JobPaused {
+		return nil
+	}
+	if err := resumeProcessGroup(job.cmd); err != nil {
+		return err
+	}
+	job.State = JobRunning
+	q.Save()
+	q.requestRebalance()
+	return nil
+}
+
+// Job returns the job with the given ID, or nil if there's none
+func (q *Queue) Job(id int) *Job {
+	for _, job := range q.Jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// Running returns the number of jobs currently running
+func (q *Queue) Running() int {
+	return q.Count(JobRunning)
+}
+
+// Active returns the number of jobs holding a worker, including paused ones
+func (q *Queue) Active() int {
+	return q.Count(JobRunning) + q.Count(JobPaused)
+}
+
+// Count returns the number of jobs in the given state
+func (q *Queue) Count(state JobState) int {
+	count := 0
+	for _, job := range q.Jobs {
+		if job.State == state {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SavedJob is an unfinished job as it's stored in queue.json
+type SavedJob struct {
+	URL          string      `json:"url"`
+	Title        string      `json:"title,omitempty"`
+	Options      []Option    `json:"options"`
+	Presets      []string    `json:"presets,omitempty"`
+	Archive      string      `json:"archive,omitempty"`
+	State        string      `json:"state"`
+	Attempts     int         `json:"attempts,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	StartAt      *time.Time  `json:"start_at,omitempty"`
+	Window       *TimeWindow `json:"window,omitempty"`
+	Hooks        []JobHook   `json:"hooks,omitempty"`
+	Destinations []string    `json:"destinations,omitempty"` // Files yt-dlp started writing, partial ones included
+}
+
+// QueueState represents the saved queue
+type QueueState struct {
+	Jobs []SavedJob `json:"jobs"`
+}
+
+// getQueueFilePath returns the path of the saved queue next to config.json
+func getQueueFilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "queue.json"), nil
+}
+
+// LoadQueueState reads the jobs left unfinished by the last session
+func LoadQueueState(path string) ([]SavedJob, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state QueueState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return state.Jobs, nil
+}
+
+// Save writes every unfinished job to StatePath so it can be resumed after a restart.
+// Finished and canceled jobs are left out.
+func (q *Queue) Save() {
+	if q.StatePath == "" {
+		return
+	}
+
+	state := QueueState{Jobs: []SavedJob{}}
+	for _, job := range q.Jobs {
+		if job.State == JobDone || job.State == JobCanceled {
+			continue
+		}
+		saved := SavedJob{
+			URL:          job.URL,
+			Title:        job.Title,
+			Options:      job.Options,
+			Presets:      job.Presets,
+			Archive:      job.Archive,
+			State:        job.State.String(),
+			Attempts:     job.Attempts,
+			Window:       job.Window,
+			Hooks:        job.Hooks,
+			Destinations: job.Progress.Destinations,
+		}
+		if job.State == JobFailed || job.State == JobRetrying {
+			saved.Error = job.Progress.Error
+		}
+		if !job.StartAt.IsZero() {
+			startAt := job.StartAt
+			saved.StartAt = &startAt
+		}
+		state.Jobs = append(state.Jobs, saved)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		logToFile("Failed to save queue: " + err.Error())
+		return
+	}
+
+	// Replace the file in one step so a crash never leaves half of it behind
+	tmpPath := q.StatePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		logToFile("Failed to save queue: " + err.Error())
+		return
+	}
+	if err := os.Rename(tmpPath, q.StatePath); err != nil {
+		logToFile("Failed to save queue: " + err.Error())
+	}
+}
+
+// Restore queues saved jobs again. Failed jobs get a fresh set of attempts,
+// and jobs that had started continue their partial files.
+func (q *Queue) Restore(saved []SavedJob) {
+	for _, s := range saved {
+		job := q.Enqueue(s.URL, s.Options, s.Presets)
+		job.Title = s.Title
+		job.Archive = s.Archive
+		job.Window = s.Window
+		job.Hooks = s.Hooks
+		if s.StartAt != nil {
+			job.StartAt = *s.StartAt
+		}
+		job.Progress.Destinations = s.Destinations
+
+		switch s.State {
+		case JobFailed.String():
+			job.Attempts = 0
+		case JobRunning.String(), JobPaused.String():
+			// The attempt that was interrupted is continued rather than counted again
+			job.Attempts = s.Attempts
+			job.partial = true
+		default:
+			job.Attempts = s.Attempts
+		}
+	}
+	logToFile(fmt.Sprintf("Restored %d jobs from the last session", len(saved)))
+}
+
+// Stop kills every running and paused job, leaving their partial files to be continued
+func (q *Queue) Stop() {
+	for _, job := range q.Jobs {
+		if job.State != JobRunning && job.State != JobPaused {
+			continue
+		}
+		if err := killProcessGroup(job.cmd); err != nil {
+			logToFile(fmt.Sprintf("Failed to stop job %d: %s", job.ID, err.Error()))
+		}
+	}
+}
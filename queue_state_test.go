@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueueSaveRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	queue := NewQueue(1)
+	queue.StatePath = path
+
+	options := []Option{{Flag: "-f best", Enabled: true}}
+	startAt := time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local)
+	window := &TimeWindow{Start: 60, End: 120}
+
+	queued := queue.Enqueue("https://example.com/queued", options, []string{"Best"})
+	queued.Title = "Queued video"
+	queued.StartAt = startAt
+	queued.Window = window
+	running := queue.Enqueue("https://example.com/running", options, nil)
+	running.State = JobRunning
+	running.Attempts = 2
+	running.Progress.Destinations = []string{"video.f137.mp4"}
+	failed := queue.Enqueue("https://example.com/failed", nil, nil)
+	failed.State = JobFailed
+	failed.Attempts = 4
+	failed.Progress.Error = "HTTP Error 403"
+	done := queue.Enqueue("https://example.com/done", nil, nil)
+	done.State = JobDone
+	canceled := queue.Enqueue("https://example.com/canceled", nil, nil)
+	canceled.State = JobCanceled
+	queue.Save()
+
+	saved, err := LoadQueueState(path)
+	if err != nil {
+		t.Fatalf("LoadQueueState() error = %v", err)
+	}
+	if len(saved) != 3 {
+		t.Fatalf("saved %d jobs, want the 3 unfinished ones", len(saved))
+	}
+	if saved[2].Error != "HTTP Error 403" {
+		t.Errorf("failed job saved with error %q", saved[2].Error)
+	}
+
+	restored := NewQueue(1)
+	restored.Restore(saved)
+	if len(restored.Jobs) != 3 {
+		t.Fatalf("restored %d jobs, want 3", len(restored.Jobs))
+	}
+
+	got := restored.Jobs[0]
+	if got.URL != queued.URL || got.Title != queued.Title || !reflect.DeepEqual(got.Options, options) ||
+		!reflect.DeepEqual(got.Presets, queued.Presets) || !got.StartAt.Equal(startAt) || !reflect.DeepEqual(got.Window, window) {
+		t.Errorf("queued job restored as %+v", got)
+	}
+	if got.State != JobQueued || got.partial {
+		t.Errorf("queued job restored as %v, partial %v", got.State, got.partial)
+	}
+
+	got = restored.Jobs[1]
+	if got.State != JobQueued || !got.partial || got.Attempts != 2 {
+		t.Errorf("running job restored as %v, partial %v, %d attempts, want queued to continue after 2", got.State, got.partial, got.Attempts)
+	}
+	if !reflect.DeepEqual(got.Progress.Destinations, running.Progress.Destinations) {
+		t.Errorf("running job destinations = %q, want %q", got.Progress.Destinations, running.Progress.Destinations)
+	}
+
+	got = restored.Jobs[2]
+	if got.State != JobQueued || got.Attempts != 0 {
+		t.Errorf("failed job restored as %v with %d attempts, want queued with none", got.State, got.Attempts)
+	}
+}
+
+func TestQueueSavesNewDestinations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	queue := NewQueue(1)
+	queue.StatePath = path
+	job := queue.Enqueue("https://example.com", nil, nil)
+	job.State = JobRunning
+
+	queue.Handle(DownloadMsg{JobID: job.ID, Progress: DownloadProgress{Destinations: []string{"video.mp4.part"}}})
+
+	saved, err := LoadQueueState(path)
+	if err != nil {
+		t.Fatalf("LoadQueueState() error = %v", err)
+	}
+	if len(saved) != 1 || !reflect.DeepEqual(saved[0].Destinations, []string{"video.mp4.part"}) {
+		t.Errorf("saved jobs = %+v, want the new destination", saved)
+	}
+}
+
+func TestLoadQueueState(t *testing.T) {
+	dir := t.TempDir()
+
+	saved, err := LoadQueueState(filepath.Join(dir, "missing.json"))
+	if saved != nil || err != nil {
+		t.Errorf("LoadQueueState(missing) = %v, %v, want nothing", saved, err)
+	}
+
+	path := filepath.Join(dir, "queue.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadQueueState(path); err == nil {
+		t.Error("LoadQueueState(invalid JSON) returned no error")
+	}
+}
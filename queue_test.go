@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestQueueEnqueueSnapshotsOptions(t *testing.T) {
+	queue := NewQueue(0)
+	if queue.Workers != 1 {
+		t.Errorf("NewQueue(0).Workers = %d, want 1", queue.Workers)
+	}
+
+	options := []Option{{Flag: "-f best", Enabled: true}}
+	first := queue.Enqueue("https://example.com/1", options, nil)
+	second := queue.Enqueue("https://example.com/2", options, nil)
+	options[0].Flag = "-x"
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("job IDs = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+	if first.Options[0].Flag != "-f best" {
+		t.Errorf("job options changed with the caller's slice: %q", first.Options[0].Flag)
+	}
+	if first.State != JobQueued || queue.Count(JobQueued) != 2 {
+		t.Errorf("jobs aren't queued: %v, %d queued", first.State, queue.Count(JobQueued))
+	}
+}
+
+func TestQueueHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       DownloadMsg
+		wantState JobState
+		wantError string
+		wantLines int
+		wantPct   string
+	}{
+		{
+			name:      "progress",
+			msg:       DownloadMsg{JobID: 1, Line: "[download] 10%", Progress: DownloadProgress{Percentage: "10%"}},
+			wantState: JobRunning,
+			wantLines: 2,
+			wantPct:   "10%",
+		},
+		{
+			name:      "finished",
+			msg:       DownloadMsg{JobID: 1, Done: true},
+			wantState: JobDone,
+			wantLines: 1,
+			wantPct:   "100%",
+		},
+		{
+			name:      "failed",
+			msg:       DownloadMsg{JobID: 1, Done: true, Error: errors.New("exit status 1")},
+			wantState: JobFailed,
+			wantError: "failed: exit status 1",
+			wantLines: 1,
+		},
+	}
+
+	for _, test := range tests {
+		queue := NewQueue(1)
+		job := queue.Enqueue("https://example.com", nil, nil)
+		job.State = JobRunning
+		job.Progress.Output = []string{"[youtube] Extracting URL"}
+
+		if got := queue.Handle(test.msg); got != job {
+			t.Fatalf("%s: Handle returned %v, want job %d", test.name, got, job.ID)
+		}
+		if job.State != test.wantState {
+			t.Errorf("%s: state = %v, want %v", test.name, job.State, test.wantState)
+		}
+		if job.Progress.Error != test.wantError {
+			t.Errorf("%s: error = %q, want %q", test.name, job.Progress.Error, test.wantError)
+		}
+		if len(job.Progress.Output) != test.wantLines {
+			t.Errorf("%s: kept %d output lines, want %d", test.name, len(job.Progress.Output), test.wantLines)
+		}
+		if job.Progress.Percentage != test.wantPct {
+			t.Errorf("%s: percentage = %q, want %q", test.name, job.Progress.Percentage, test.wantPct)
+		}
+	}
+}
+
+func TestQueueHandleUnknownJob(t *testing.T) {
+	queue := NewQueue(1)
+	queue.Enqueue("https://example.com", nil, nil)
+	if job := queue.Handle(DownloadMsg{JobID: 42}); job != nil {
+		t.Errorf("Handle of an unknown job returned job %d", job.ID)
+	}
+}
+
+func TestQueueHandleTrimsOutput(t *testing.T) {
+	queue := NewQueue(1)
+	job := queue.Enqueue("https://example.com", nil, nil)
+	for i := 0; i < maxOutputLines+5; i++ {
+		queue.Handle(DownloadMsg{JobID: job.ID, Line: fmt.Sprintf("line %d", i)})
+	}
+
+	if len(job.Progress.Output) != maxOutputLines {
+		t.Fatalf("kept %d output lines, want %d", len(job.Progress.Output), maxOutputLines)
+	}
+	if last := job.Progress.Output[maxOutputLines-1]; last != fmt.Sprintf("line %d", maxOutputLines+4) {
+		t.Errorf("last output line = %q", last)
+	}
+}
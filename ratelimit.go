@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minRateShare is the lowest rate a single job is limited to, in bytes per second
+const minRateShare = 16 * 1024
+
+// rebalanceDelay is how long the set of running jobs has to stay the same before
+// their rate limits are adjusted, so a burst of starts restarts every job once
+const rebalanceDelay = 5 * time.Second
+
+// rateUnits are the suffixes yt-dlp accepts for --limit-rate, as powers of 1024
+var rateUnits = map[string]int{"": 0, "K": 1, "M": 2, "G": 3}
+
+// parseRate parses a rate like "5M" or "500K" into bytes per second.
+// An empty string means no limit and returns 0.
+func parseRate(rate string) (float64, error) {
+	rate = strings.TrimSpace(rate)
+	if rate == "" {
+		return 0, nil
+	}
+
+	// Accept "5M", "5MB", "5M/s" and "5MiB/s"
+	number := strings.ToUpper(strings.TrimSuffix(rate, "/s"))
+	number = strings.TrimSuffix(strings.TrimSuffix(number, "B"), "I")
+	if number == "" {
+		return 0, fmt.Errorf("invalid rate %q, expected e.g. 500K or 5M", rate)
+	}
+	unit := ""
+	if last := number[len(number)-1:]; last < "0" || last > "9" {
+		unit = last
+		number = number[:len(number)-1]
+	}
+
+	exponent, ok := rateUnits[unit]
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected e.g. 500K or 5M", rate)
+	}
+	return value * math.Pow(1024, float64(exponent)), nil
+}
+
+// formatRate formats bytes per second the way --limit-rate expects them
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%dK", int64(math.Max(rate/1024, 1)))
+}
+
+// isRateLimitFlag reports whether the arguments of an option set --limit-rate, returning its value
+func isRateLimitFlag(parts []string) (string, bool) {
+	if len(parts) == 0 {
+		return "", false
+	}
+
+	key, value, hasValue := strings.Cut(parts[0], "=")
+	if key != "--limit-rate" && key != "-r" {
+		return "", false
+	}
+	if !hasValue && len(parts) > 1 {
+		value = parts[1]
+	}
+	return value, true
+}
+
+// applyRateLimit limits options to rate bytes per second.
+// A lower --limit-rate set by a preset is kept, higher ones are replaced.
+func applyRateLimit(options []Option, rate float64) []Option {
+	if rate <= 0 || math.IsInf(rate, 1) || optionsRate(options) <= rate {
+		return options
+	}
+
+	var result []Option
+	for _, option := range options {
+		if _, ok := isRateLimitFlag(option.Arguments()); !ok {
+			result = append(result, option)
+		}
+	}
+
+	return append(result, Option{
+		Flag:    "--limit-rate=" + formatRate(rate),
+		Comment: "Share of the global rate limit",
+		Enabled: true,
+		Source:  sourceRateLimit,
+	})
+}
+
+// optionsRate returns the lowest --limit-rate set in options, or +Inf if there's none
+func optionsRate(options []Option) float64 {
+	rate := math.Inf(1)
+	for _, option := range options {
+		if value, ok := isRateLimitFlag(option.Arguments()); ok && option.Enabled {
+			if own, err := parseRate(value); err == nil {
+				rate = math.Min(rate, own)
+			}
+		}
+	}
+	return rate
+}
+
+// rateShare splits the global rate limit evenly among running jobs, returning +Inf if there's no limit
+func (q *Queue) rateShare(running int) float64 {
+	if q.RateLimit <= 0 {
+		return math.Inf(1)
+	}
+	return math.Max(q.RateLimit/float64(max(running, 1)), minRateShare)
+}
+
+// requestRebalance adjusts the rate limits of running jobs once no job started or stopped for rebalanceDelay
+func (q *Queue) requestRebalance() {
+	if q.RateLimit <= 0 {
+		return
+	}
+	q.rebalanceAt = time.Now().Add(rebalanceDelay)
+}
+
+// rebalanceIfSettled rebalances the running jobs if a requested rebalance is due at now
+func (q *Queue) rebalanceIfSettled(now time.Time) {
+	if q.rebalanceAt.IsZero() || now.Before(q.rebalanceAt) {
+		return
+	}
+	q.rebalanceAt = time.Time{}
+	q.Rebalance()
+}
+
+// Rebalance restarts running jobs whose rate limit no longer matches their share of the global limit.
+// Jobs above their share are always restarted, jobs well below it only while still downloading.
+func (q *Queue) Rebalance() {
+	if q.RateLimit <= 0 {
+		return
+	}
+
+	share := q.rateShare(q.Running())
+	for _, job := range q.Jobs {
+		if job.State != JobRunning || job.restarting || job.Progress.Percent() >= 1 {
+			continue
+		}
+		want := math.Min(share, optionsRate(job.Options))
+		if job.RateLimit > want*1.05 || job.RateLimit < want*0.75 {
+			logToFile(fmt.Sprintf("Restarting job %d to limit it to %s/s", job.ID, formatRate(want)))
+			job.restarting = true
+			if err := killProcessGroup(job.cmd); err != nil {
+				job.restarting = false
+				logToFile(fmt.Sprintf("Failed to restart job %d: %s", job.ID, err.Error()))
+			}
+		}
+	}
+}
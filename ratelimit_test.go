@@ -0,0 +1,159 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate    string
+		want    float64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"500K", 500 * 1024, false},
+		{"5M", 5 * 1024 * 1024, false},
+		{"1.5M", 1.5 * 1024 * 1024, false},
+		{"5MiB/s", 5 * 1024 * 1024, false},
+		{"5mb", 5 * 1024 * 1024, false},
+		{"1G", 1024 * 1024 * 1024, false},
+		{"2048", 2048, false},
+		{" 5M ", 5 * 1024 * 1024, false},
+		{"5 megs", 0, true},
+		{"5T", 0, true},
+		{"-1M", 0, true},
+		{"0", 0, true},
+		{"M", 0, true},
+		{"B", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := parseRate(test.rate)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseRate(%q) error = %v, want error %v", test.rate, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseRate(%q) = %v, want %v", test.rate, got, test.want)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want string
+	}{
+		{5 * 1024 * 1024, "5120K"},
+		{1536, "1K"},
+		{100, "1K"},
+	}
+
+	for _, test := range tests {
+		if got := formatRate(test.rate); got != test.want {
+			t.Errorf("formatRate(%v) = %q, want %q", test.rate, got, test.want)
+		}
+	}
+}
+
+func TestApplyRateLimit(t *testing.T) {
+	format := Option{Flag: "-f best", Enabled: true}
+	share := Option{Flag: "--limit-rate=1024K", Comment: "Share of the global rate limit", Enabled: true, Source: sourceRateLimit}
+
+	tests := []struct {
+		name    string
+		options []Option
+		rate    float64
+		want    []Option
+	}{
+		{"no limit", []Option{format}, 0, []Option{format}},
+		{"unlimited share", []Option{format}, math.Inf(1), []Option{format}},
+		{"added", []Option{format}, 1024 * 1024, []Option{format, share}},
+		{
+			"higher preset limit replaced",
+			[]Option{{Flag: "--limit-rate 5M", Enabled: true}, format},
+			1024 * 1024,
+			[]Option{format, share},
+		},
+		{
+			"short flag replaced",
+			[]Option{{Flag: "-r 5M", Enabled: true}, format},
+			1024 * 1024,
+			[]Option{format, share},
+		},
+		{
+			"lower preset limit kept",
+			[]Option{{Flag: "--limit-rate=500K", Enabled: true}, format},
+			1024 * 1024,
+			[]Option{{Flag: "--limit-rate=500K", Enabled: true}, format},
+		},
+		{
+			"disabled preset limit ignored",
+			[]Option{{Flag: "--limit-rate=500K", Enabled: false}, format},
+			1024 * 1024,
+			[]Option{format, share},
+		},
+	}
+
+	for _, test := range tests {
+		if got := applyRateLimit(test.options, test.rate); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: applyRateLimit() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRateShare(t *testing.T) {
+	tests := []struct {
+		limit   float64
+		running int
+		want    float64
+	}{
+		{0, 3, math.Inf(1)},
+		{3 * 1024 * 1024, 0, 3 * 1024 * 1024},
+		{3 * 1024 * 1024, 1, 3 * 1024 * 1024},
+		{3 * 1024 * 1024, 3, 1024 * 1024},
+		{64 * 1024, 10, minRateShare},
+	}
+
+	for _, test := range tests {
+		queue := NewQueue(3)
+		queue.RateLimit = test.limit
+		if got := queue.rateShare(test.running); got != test.want {
+			t.Errorf("rateShare(%d) with limit %v = %v, want %v", test.running, test.limit, got, test.want)
+		}
+	}
+}
+
+func TestRequestRebalanceWaitsForRunningJobsToSettle(t *testing.T) {
+	queue := NewQueue(3)
+	queue.requestRebalance()
+	if !queue.rebalanceAt.IsZero() {
+		t.Error("rebalance requested without a rate limit")
+	}
+
+	queue.RateLimit = 1024 * 1024
+	queue.requestRebalance()
+	first := queue.rebalanceAt
+	if first.IsZero() {
+		t.Fatal("no rebalance requested")
+	}
+
+	// Every change of the running jobs pushes the rebalance back
+	time.Sleep(time.Millisecond)
+	queue.requestRebalance()
+	if !queue.rebalanceAt.After(first) {
+		t.Error("another change didn't push the rebalance back")
+	}
+
+	queue.rebalanceIfSettled(queue.rebalanceAt.Add(-time.Second))
+	if queue.rebalanceAt.IsZero() {
+		t.Error("rebalanced before the running jobs settled")
+	}
+	queue.rebalanceIfSettled(queue.rebalanceAt)
+	if !queue.rebalanceAt.IsZero() {
+		t.Error("settled rebalance is still pending")
+	}
+}
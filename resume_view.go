@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var resumeAppStyle = lipgloss.NewStyle().Padding(1, 2)
+
+// maxListedJobs limits how many saved jobs are listed in the resume prompt
+const maxListedJobs = 10
+
+// ResumeView asks whether to resume the jobs left unfinished by the last session
+type ResumeView struct {
+	Jobs []SavedJob
+}
+
+// ResumeQueueMsg is sent once the user decided what to do with the saved jobs
+type ResumeQueueMsg struct {
+	Resume bool
+	Jobs   []SavedJob
+}
+
+// NewResumeView creates a new ResumeView for the saved jobs
+func NewResumeView(jobs []SavedJob) ResumeView {
+	return ResumeView{Jobs: jobs}
+}
+
+// Update handles input for the ResumeView
+func (rv *ResumeView) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter", "y", "Y":
+			return rv.decide(true)
+		case "n", "N":
+			return rv.decide(false)
+		}
+	}
+
+	return nil
+}
+
+// decide returns a command reporting the decision with the saved jobs
+func (rv *ResumeView) decide(resume bool) tea.Cmd {
+	jobs := rv.Jobs
+	return func() tea.Msg {
+		return ResumeQueueMsg{Resume: resume, Jobs: jobs}
+	}
+}
+
+// View renders the ResumeView
+func (rv ResumeView) View() string {
+	s := focusedLabelStyle.Render(fmt.Sprintf("%d downloads weren't finished last time:", len(rv.Jobs))) + "\n\n"
+
+	faint := lipgloss.NewStyle().Faint(true)
+	for i, job := range rv.Jobs {
+		if i == maxListedJobs {
+			s += faint.Render(fmt.Sprintf("...and %d more", len(rv.Jobs)-maxListedJobs)) + "\n"
+			break
+		}
+		name := job.URL
+		if job.Title != "" {
+			name = job.Title
+		}
+		s += "• " + name + faint.Render(" ("+job.State+")") + "\n"
+		if job.Error != "" {
+			s += faint.Render("  "+job.Error) + "\n"
+		}
+	}
+
+	s += "\n" + faint.Render("Started downloads continue their partial files, failed ones are tried again")
+
+	return resumeAppStyle.Render(s)
+}
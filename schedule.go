@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TimeWindow is a daily period in minutes since midnight; End before Start wraps past midnight
+type TimeWindow struct {
+	Start int
+	End   int
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(text string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", strings.TrimSpace(text))
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// formatClock formats minutes since midnight as HH:MM
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// parseSchedule parses "HH:MM" into a start time and "HH:MM-HH:MM" into a daily window.
+// An empty text clears both.
+func parseSchedule(text string, now time.Time) (time.Time, *TimeWindow, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil, nil
+	}
+
+	if start, end, ok := strings.Cut(text, "-"); ok {
+		startMinutes, err := parseClock(start)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		endMinutes, err := parseClock(end)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		if startMinutes == endMinutes {
+			return time.Time{}, nil, fmt.Errorf("window %s is empty", text)
+		}
+		return time.Time{}, &TimeWindow{Start: startMinutes, End: endMinutes}, nil
+	}
+
+	minutes, err := parseClock(text)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return nextClock(now, minutes), nil, nil
+}
+
+// nextClock returns the next time at minutes since midnight, today or tomorrow
+func nextClock(now time.Time, minutes int) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(time.Duration(minutes) * time.Minute)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Contains reports whether the window is open at t
+func (w TimeWindow) Contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return minutes >= w.Start && minutes < w.End
+	}
+	return minutes >= w.Start || minutes < w.End
+}
+
+// NextOpen returns when the window opens next, or now if it's open
+func (w TimeWindow) NextOpen(now time.Time) time.Time {
+	if w.Contains(now) {
+		return now
+	}
+	return nextClock(now, w.Start)
+}
+
+// String formats the window as HH:MM-HH:MM
+func (w TimeWindow) String() string {
+	return formatClock(w.Start) + "-" + formatClock(w.End)
+}
+
+// ReadyAt returns when the job's schedule allows it to run
+func (j *Job) ReadyAt(now time.Time) time.Time {
+	ready := now
+	if j.StartAt.After(ready) {
+		ready = j.StartAt
+	}
+	if j.Window != nil {
+		ready = j.Window.NextOpen(ready)
+	}
+	return ready
+}
+
+// Ready reports whether the job's schedule allows it to run now
+func (j *Job) Ready(now time.Time) bool {
+	return !j.ReadyAt(now).After(now)
+}
+
+// Waiting reports whether a queued job is held back by its schedule
+func (j *Job) Waiting(now time.Time) bool {
+	return j.State == JobQueued && !j.Ready(now)
+}
+
+// ScheduleText returns the job's schedule in the format parseSchedule accepts
+func (j *Job) ScheduleText() string {
+	switch {
+	case j.Window != nil:
+		return j.Window.String()
+	case !j.StartAt.IsZero():
+		return j.StartAt.Format("15:04")
+	default:
+		return ""
+	}
+}
+
+// ScheduleDescription describes the job's schedule for the dashboard
+func (j *Job) ScheduleDescription() string {
+	switch {
+	case j.Window != nil:
+		return fmt.Sprintf("Runs only between %s and %s", formatClock(j.Window.Start), formatClock(j.Window.End))
+	case !j.StartAt.IsZero():
+		return "Starts at " + j.StartAt.Format("2006-01-02 15:04")
+	default:
+		return ""
+	}
+}
+
+// queueTickCmd wakes the queue up a second later to start jobs that became ready
+func queueTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return queueTickMsg{}
+	})
+}
+
+// NeedsTick reports whether any job waits for a retry or its schedule,
+// or runs in a window that will close
+func (q *Queue) NeedsTick() bool {
+	now := time.Now()
+	for _, job := range q.Jobs {
+		switch {
+		case job.State == JobRetrying, job.Waiting(now):
+			return true
+		case job.State == JobRunning && job.Window != nil:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2024, 5, 1, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		text       string
+		wantStart  time.Time
+		wantWindow *TimeWindow
+		wantErr    bool
+	}{
+		{"", time.Time{}, nil, false},
+		{"18:00", time.Date(2024, 5, 1, 18, 0, 0, 0, time.Local), nil, false},
+		{" 9:05 ", time.Date(2024, 5, 2, 9, 5, 0, 0, time.Local), nil, false},
+		{"14:30", time.Date(2024, 5, 2, 14, 30, 0, 0, time.Local), nil, false},
+		{"01:00-06:00", time.Time{}, &TimeWindow{Start: 60, End: 360}, false},
+		{"23:00 - 07:30", time.Time{}, &TimeWindow{Start: 1380, End: 450}, false},
+		{"06:00-06:00", time.Time{}, nil, true},
+		{"25:00", time.Time{}, nil, true},
+		{"6pm", time.Time{}, nil, true},
+		{"01:00-", time.Time{}, nil, true},
+	}
+
+	for _, test := range tests {
+		start, window, err := parseSchedule(test.text, now)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseSchedule(%q) error = %v, want error %v", test.text, err, test.wantErr)
+			continue
+		}
+		if !start.Equal(test.wantStart) {
+			t.Errorf("parseSchedule(%q) start = %s, want %s", test.text, start, test.wantStart)
+		}
+		if (window == nil) != (test.wantWindow == nil) || (window != nil && *window != *test.wantWindow) {
+			t.Errorf("parseSchedule(%q) window = %v, want %v", test.text, window, test.wantWindow)
+		}
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2024, 5, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		window   TimeWindow
+		now      time.Time
+		wantOpen bool
+		wantNext time.Time
+	}{
+		{TimeWindow{Start: 60, End: 360}, day(3, 0), true, day(3, 0)},
+		{TimeWindow{Start: 60, End: 360}, day(1, 0), true, day(1, 0)},
+		{TimeWindow{Start: 60, End: 360}, day(6, 0), false, day(1, 0).AddDate(0, 0, 1)},
+		{TimeWindow{Start: 60, End: 360}, day(0, 30), false, day(1, 0)},
+		// Windows past midnight
+		{TimeWindow{Start: 1380, End: 420}, day(23, 30), true, day(23, 30)},
+		{TimeWindow{Start: 1380, End: 420}, day(2, 0), true, day(2, 0)},
+		{TimeWindow{Start: 1380, End: 420}, day(12, 0), false, day(23, 0)},
+	}
+
+	for _, test := range tests {
+		if got := test.window.Contains(test.now); got != test.wantOpen {
+			t.Errorf("%s.Contains(%s) = %v, want %v", test.window, test.now.Format("15:04"), got, test.wantOpen)
+		}
+		if got := test.window.NextOpen(test.now); !got.Equal(test.wantNext) {
+			t.Errorf("%s.NextOpen(%s) = %s, want %s", test.window, test.now.Format("15:04"), got, test.wantNext)
+		}
+	}
+}
+
+func TestJobReady(t *testing.T) {
+	now := time.Date(2024, 5, 1, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		job       Job
+		wantReady time.Time
+	}{
+		{"no schedule", Job{}, now},
+		{"start time", Job{StartAt: now.Add(time.Hour)}, now.Add(time.Hour)},
+		{"start time passed", Job{StartAt: now.Add(-time.Hour)}, now},
+		{"open window", Job{Window: &TimeWindow{Start: 840, End: 900}}, now},
+		{"closed window", Job{Window: &TimeWindow{Start: 1320, End: 360}}, time.Date(2024, 5, 1, 22, 0, 0, 0, time.Local)},
+		{
+			"start time in closed window",
+			Job{StartAt: now.Add(time.Hour), Window: &TimeWindow{Start: 840, End: 900}},
+			time.Date(2024, 5, 2, 14, 0, 0, 0, time.Local),
+		},
+	}
+
+	for _, test := range tests {
+		got := test.job.ReadyAt(now)
+		if !got.Equal(test.wantReady) {
+			t.Errorf("%s: ReadyAt() = %s, want %s", test.name, got, test.wantReady)
+		}
+		if ready := test.job.Ready(now); ready != got.Equal(now) {
+			t.Errorf("%s: Ready() = %v, want %v", test.name, ready, got.Equal(now))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned by splitShellWords
+var (
+	errUnbalancedQuote = errors.New("unbalanced quote")
+	errTrailingEscape  = errors.New("backslash at the end")
+)
+
+// splitShellWords splits text into words the way a POSIX shell would, without expanding anything.
+// Single quotes keep everything literal, double quotes allow escaping ", \, $ and `,
+// and a backslash outside quotes escapes the next character.
+func splitShellWords(text string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 == len(text) {
+				return nil, errTrailingEscape
+			}
+			i++
+			word.WriteByte(text[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, errUnbalancedQuote
+			}
+			word.WriteString(text[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) && strings.IndexByte("\"\\$`", text[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(text[i])
+			}
+			if i == len(text) {
+				return nil, errUnbalancedQuote
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// validateFlag reports why a flag can't be split into arguments, or nil if it can
+func validateFlag(flag string) error {
+	words, err := splitShellWords(flag)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return errors.New("flag is empty")
+	}
+	return nil
+}
+
+// Arguments returns the command line arguments of the option, splitting Flag like a shell would
+func (o Option) Arguments() []string {
+	if o.Args != nil {
+		return o.Args
+	}
+	args, err := splitShellWords(o.Flag)
+	if err != nil {
+		// Flags saved before they were validated
+		return strings.Fields(o.Flag)
+	}
+	return args
+}
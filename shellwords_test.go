@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []string
+		wantErr error
+	}{
+		{"", nil, nil},
+		{"   ", nil, nil},
+		{"--format best", []string{"--format", "best"}, nil},
+		{"  --format \t best  ", []string{"--format", "best"}, nil},
+		{`-o "%(title)s - %(id)s.%(ext)s"`, []string{"-o", "%(title)s - %(id)s.%(ext)s"}, nil},
+		{`--match-filter 'duration > 60'`, []string{"--match-filter", "duration > 60"}, nil},
+		// Quotes inside the other kind of quotes are literal
+		{`--match-filter "title ~= 'live'"`, []string{"--match-filter", "title ~= 'live'"}, nil},
+		{`--exec 'echo "done"'`, []string{"--exec", `echo "done"`}, nil},
+		// Escapes inside double quotes, but not inside single quotes
+		{`"say \"hi\" for \$5"`, []string{`say "hi" for $5`}, nil},
+		{`"C:\path\to"`, []string{`C:\path\to`}, nil},
+		{`'a\"b'`, []string{`a\"b`}, nil},
+		// Quoted parts join the word around them
+		{`a'b c'"d e"f`, []string{"ab cd ef"}, nil},
+		{`--output=""`, []string{"--output="}, nil},
+		{`""`, []string{""}, nil},
+		{`it\'s here\ too`, []string{"it's", "here too"}, nil},
+		{`--format "best`, nil, errUnbalancedQuote},
+		{`--format 'best`, nil, errUnbalancedQuote},
+		{`"it's`, nil, errUnbalancedQuote},
+		{`"a \"b"c"`, nil, errUnbalancedQuote},
+		{`--format best\`, nil, errTrailingEscape},
+	}
+
+	for _, test := range tests {
+		got, err := splitShellWords(test.text)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("splitShellWords(%q) error = %v, want %v", test.text, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitShellWords(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestValidateFlag(t *testing.T) {
+	tests := []struct {
+		flag  string
+		valid bool
+	}{
+		{"--format=best", true},
+		{`-o "%(title)s.%(ext)s"`, true},
+		{"", false},
+		{"   ", false},
+		{`--format "best`, false},
+		{`--format best\`, false},
+	}
+
+	for _, test := range tests {
+		if err := validateFlag(test.flag); (err == nil) != test.valid {
+			t.Errorf("validateFlag(%q) = %v, want valid %v", test.flag, err, test.valid)
+		}
+	}
+}
+
+func TestOptionArguments(t *testing.T) {
+	tests := []struct {
+		option Option
+		want   []string
+	}{
+		{Option{Flag: `--match-filter "duration > 60"`}, []string{"--match-filter", "duration > 60"}},
+		// Args set when parsing the command line win over the flag
+		{Option{Flag: "ignored", Args: []string{"-f", "-bestaudio"}}, []string{"-f", "-bestaudio"}},
+		// Flags saved before they were validated are split on spaces
+		{Option{Flag: `--format "best`}, []string{"--format", `"best`}},
+	}
+
+	for _, test := range tests {
+		if got := test.option.Arguments(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v.Arguments() = %q, want %q", test.option, got, test.want)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"time"
+
 	"github.com/76creates/stickers/flexbox"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textinput"
 )
 
@@ -13,6 +16,9 @@ type Option struct {
 	Flag    string `json:"flag"`
 	Comment string `json:"comment"`
 	Enabled bool   `json:"enabled"`
+	Source  string `json:"-"` // Preset or other origin of the option once merged
+	// Args are the exact arguments of options generated by babago, used instead of splitting Flag
+	Args []string `json:"args,omitempty"`
 }
 
 // Preset represents a configuration preset
@@ -20,6 +26,11 @@ type Preset struct {
 	Name    string   `json:"name"`
 	Options []Option `json:"options"`
 	Active  bool     `json:"active"`
+	Archive string   `json:"archive,omitempty"` // Download archive used instead of the global one
+	Hooks   []Hook   `json:"hooks,omitempty"`   // Commands run after every successful download
+	// OutputDir and OutputTemplate set where downloads are saved, passed as --paths and --output
+	OutputDir      string `json:"output_dir,omitempty"`
+	OutputTemplate string `json:"output_template,omitempty"`
 }
 
 // TabMode represents which tab is currently active
@@ -28,6 +39,7 @@ type TabMode int
 const (
 	URLTab TabMode = iota
 	PresetsTab
+	DownloadsTab
 )
 
 // ViewMode represents the view state
@@ -37,6 +49,12 @@ const (
 	MainView ViewMode = iota
 	EditPresetView
 	AddOptionViewMode
+	FormatPickerView
+	PlaylistPickerView
+	ImportFileView
+	PasteModeView
+	ResumePromptView
+	ConflictReportView
 )
 
 // FocusState represents what element has focus in URLView
@@ -46,26 +64,47 @@ const (
 	FocusInput FocusState = iota
 	FocusDownloadButton
 	FocusPresetsButton
+	FocusDownloadsButton
 )
 
 // URLView handles the URL input interface
 type URLView struct {
-	URLInput        textinput.Model
-	CurrentURL      string
-	IsValidURL      bool
-	URLHistory      []string
-	HistoryNames    []string // Names for history URLs
-	HistoryIndex    int
-	IsInHistory     bool
-	FlexBox         *flexbox.FlexBox // For centering the input
-	FocusState      FocusState       // Which element has focus
-	LastButtonFocus FocusState       // Remembers last focused button
+	URLInput           textinput.Model
+	CurrentURL         string
+	IsValidURL         bool
+	URLHistory         []string
+	HistoryNames       []string // Names for history URLs
+	HistoryIndex       int
+	IsInHistory        bool
+	FlexBox            *flexbox.FlexBox          // For centering the input
+	FocusState         FocusState                // Which element has focus
+	LastButtonFocus    FocusState                // Remembers last focused button
+	Metadata           map[string]*MetadataEntry // Prefetched yt-dlp metadata by URL
+	PickedFormats      map[string]string         // Formats picked in the format list by URL
+	Duplicate          *ArchiveRecord            // Archive record if the current URL was downloaded before
+	DuplicateURL       string                    // URL Duplicate was looked up for
+	DuplicateConfirmed bool                      // Download was pressed once despite the warning
+	CommandPreview     string                    // Rendered command preview, empty when hidden
 }
 
 // PresetsView handles the main presets list interface
 type PresetsView struct {
 	Presets []Preset
 	List    list.Model
+	Catalog *OptionCatalog // Options of the installed yt-dlp for merging aliases, nil until loaded
+}
+
+// DownloadsView handles the downloads dashboard
+type DownloadsView struct {
+	Queue         *Queue
+	Keys          keyMap
+	Cursor        int             // Index of the selected job
+	Offset        int             // Index of the first visible job
+	Height        int             // Available terminal height
+	ProgressBar   progress.Model  // Bar rendered in every job row
+	Status        string          // Result of the last action
+	ScheduleInput textinput.Model // Start time or window of the selected job
+	Scheduling    bool            // Whether the schedule input is open
 }
 
 // PresetView handles editing a single preset
@@ -77,6 +116,13 @@ type PresetView struct {
 	CommentInput    textinput.Model
 	PresetNameInput textinput.Model // For adding new presets
 	InputFocus      int             // 0=flag, 1=comment, 2=options list, 3=preset name, 4=add button
+	// Output settings editor
+	OutputDirInput      textinput.Model
+	OutputTemplateInput textinput.Model
+	EditingOutput       bool           // Whether the output settings editor is open
+	OutputFocus         int            // 0=directory, 1=template
+	Sample              *VideoMetadata // Prefetched video the filename preview uses, nil for sample data
+	Catalog             *OptionCatalog // Options of the installed yt-dlp, nil until loaded
 }
 
 // keyMap defines keybindings for different contexts
@@ -88,6 +134,14 @@ type keyMap struct {
 	Backspace key.Binding
 	Delete    key.Binding
 	Download  key.Binding
+	Formats   key.Binding
+	Import    key.Binding
+	Paste     key.Binding
+	Command   key.Binding
+	Cancel    key.Binding
+	Pause     key.Binding
+	Resume    key.Binding
+	Schedule  key.Binding
 	Help      key.Binding
 	Quit      key.Binding
 }
@@ -101,7 +155,8 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Enter, k.Space},
-		{k.Backspace, k.Delete, k.Download},
+		{k.Backspace, k.Delete, k.Download, k.Formats, k.Import, k.Paste, k.Command},
+		{k.Cancel, k.Pause, k.Resume, k.Schedule},
 		{k.Help},
 	}
 }
@@ -126,16 +181,32 @@ type DownloadProgress struct {
 	State      DownloadState
 	Output     []string
 	Error      string
+	StartedAt  time.Time
+	// Destinations lists every file yt-dlp announced writing to
+	Destinations []string
+	// Files lists the final paths reported by yt-dlp after moving each file into place
+	Files []string
+	// ArchiveKeys lists the "extractor id" archive key of every finished file, in the order of Files
+	ArchiveKeys []string
+	// Titles lists the title of every finished file, in the order of Files
+	Titles []string
+	// Errors and Warnings hold the ERROR: and WARNING: lines printed by yt-dlp
+	Errors   []YtDlpMessage
+	Warnings []YtDlpMessage
 }
 
 // DownloadMsg is sent when download progress updates
 type DownloadMsg struct {
+	JobID    int // Queue job the progress belongs to
 	Progress DownloadProgress
 	Line     string
 	Done     bool
 	Error    error
 }
 
+// queueTickMsg is sent every second while jobs wait for a retry or their schedule
+type queueTickMsg struct{}
+
 // SwitchTabMsg is sent when switching tabs
 type SwitchTabMsg struct {
 	Tab TabMode
@@ -150,6 +221,11 @@ type AddOptionMsg struct {
 	Comment string
 }
 
+// EnqueueURLsMsg is sent when several URLs should be queued with the active presets
+type EnqueueURLsMsg struct {
+	URLs []string
+}
+
 // CancelAddOptionMsg is sent when canceling add option
 type CancelAddOptionMsg struct{}
 
@@ -160,11 +236,24 @@ type Model struct {
 	PresetsView   PresetsView
 	PresetView    PresetView
 	AddOptionView AddOptionView
+	DownloadsView DownloadsView
+	FormatView    FormatView
+	PlaylistView  PlaylistView
+	ImportView    ImportView
+	PasteView     PasteView
+	ResumeView    ResumeView
+	ConflictView  ConflictView
 	CurrentView   ViewMode // MainView for PresetsView, EditPresetView for PresetView
-	Download      DownloadProgress
+	Queue         *Queue
+	ProgressBar   progress.Model
 	Width         int // Terminal width
 	Height        int // Terminal height
 	Keys          keyMap
 	Help          help.Model
-	ShowHelp      bool // Whether help is expanded
+	ShowHelp      bool   // Whether help is expanded
+	QueueTicking  bool   // Whether the queue is woken up every second
+	YtDlpVersion  string // Version reported by yt-dlp --version
+	YtDlpWarning  string // Shown when yt-dlp is missing or outdated
+	SettingsError string // Shown when config.json has invalid settings
+	ShowCommand   bool   // Whether the command preview panel is shown
 }
@@ -62,24 +62,40 @@ func NewURLView() URLView {
 		FlexBox:         flexBox,
 		FocusState:      FocusInput,          // Start with input focused
 		LastButtonFocus: FocusDownloadButton, // Default to Download button
+		Metadata:        make(map[string]*MetadataEntry),
+		PickedFormats:   make(map[string]string),
 	}
 }
 
 // Update handles input for the URLView
 func (uv *URLView) Update(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
+	previousURL := uv.CurrentURL
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		// Update flexbox size on window resize
 		uv.FlexBox.SetWidth(msg.Width)
 		uv.FlexBox.SetHeight(msg.Height)
+	case metadataDebounceMsg:
+		// Only fetch if the URL is still there once typing has stopped
+		if msg.URL == uv.CurrentURL && uv.Metadata[msg.URL] == nil {
+			uv.Metadata[msg.URL] = &MetadataEntry{Loading: true}
+			return fetchMetadataCmd(msg.URL, msg.Args)
+		}
+	case MetadataMsg:
+		entry := &MetadataEntry{Info: msg.Info}
+		if msg.Err != nil {
+			logToFile("Failed to fetch metadata: " + msg.Err.Error())
+			entry.Err = msg.Err.Error()
+		}
+		uv.Metadata[msg.URL] = entry
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "up":
 			// Handle navigation up
 			switch uv.FocusState {
-			case FocusDownloadButton, FocusPresetsButton:
+			case FocusDownloadButton, FocusPresetsButton, FocusDownloadsButton:
 				// From buttons go back to input
 				uv.FocusState = FocusInput
 				uv.URLInput.Focus()
@@ -129,6 +145,9 @@ func (uv *URLView) Update(msg tea.Msg) tea.Cmd {
 			case FocusPresetsButton:
 				uv.FocusState = FocusDownloadButton
 				uv.LastButtonFocus = FocusDownloadButton // Remember Download button
+			case FocusDownloadsButton:
+				uv.FocusState = FocusPresetsButton
+				uv.LastButtonFocus = FocusPresetsButton // Remember Presets button
 			}
 		case "right":
 			// Handle navigation right
@@ -136,6 +155,9 @@ func (uv *URLView) Update(msg tea.Msg) tea.Cmd {
 			case FocusDownloadButton:
 				uv.FocusState = FocusPresetsButton
 				uv.LastButtonFocus = FocusPresetsButton // Remember Presets button
+			case FocusPresetsButton:
+				uv.FocusState = FocusDownloadsButton
+				uv.LastButtonFocus = FocusDownloadsButton // Remember Downloads button
 			}
 		case "enter", " ":
 			// Handle button actions
@@ -155,6 +177,11 @@ func (uv *URLView) Update(msg tea.Msg) tea.Cmd {
 				return tea.Cmd(func() tea.Msg {
 					return SwitchTabMsg{Tab: PresetsTab}
 				})
+			case FocusDownloadsButton:
+				// Return a command to switch to downloads tab
+				return tea.Cmd(func() tea.Msg {
+					return SwitchTabMsg{Tab: DownloadsTab}
+				})
 			}
 		case "ctrl+l":
 			// Clear the input
@@ -182,6 +209,11 @@ func (uv *URLView) Update(msg tea.Msg) tea.Cmd {
 		}
 	}
 
+	// Prefetch metadata whenever a new valid URL shows up
+	if uv.CurrentURL != previousURL && uv.IsValidURL {
+		return tea.Batch(cmd, debounceMetadataCmd(uv.CurrentURL))
+	}
+
 	return cmd
 }
 
@@ -201,11 +233,40 @@ func (uv URLView) View() string {
 			statusStyle = statusStyle.Foreground(lipgloss.Color("10")) // Green
 
 			// Show different text based on whether it's from history
-			if uv.IsInHistory && uv.HistoryIndex >= 0 && uv.HistoryIndex < len(uv.HistoryNames) && uv.HistoryNames[uv.HistoryIndex] != "" {
+			entry := uv.Metadata[uv.CurrentURL]
+			if entry != nil && entry.Info != nil {
+				statusContent = uv.renderMetadataCard(entry.Info)
+			} else if uv.IsInHistory && uv.HistoryIndex >= 0 && uv.HistoryIndex < len(uv.HistoryNames) && uv.HistoryNames[uv.HistoryIndex] != "" {
 				statusContent = statusStyle.Render(uv.HistoryNames[uv.HistoryIndex])
 			} else {
 				statusContent = statusStyle.Render("✓ Current URL: " + uv.CurrentURL)
 			}
+
+			// Let the user know what happened to the metadata
+			faint := lipgloss.NewStyle().Faint(true)
+			if entry != nil && entry.Loading {
+				statusContent += "\n" + faint.Render("Fetching video info...")
+			} else if entry != nil && entry.Err != "" {
+				statusContent += "\n" + faint.Render("Couldn't fetch video info: "+entry.Err)
+			}
+
+			// Warn before downloading the same video again
+			if uv.Duplicate != nil {
+				warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
+				statusContent += "\n" + warningStyle.Render("⚠ "+uv.Duplicate.Describe())
+				if uv.DuplicateConfirmed {
+					statusContent += "\n" + warningStyle.Bold(true).Render("Press Enter again to download anyway")
+				} else {
+					statusContent += "\n" + faint.Render("Enter twice: download anyway")
+				}
+			}
+
+			// Show the picked format, or how to pick one
+			if format, ok := uv.PickedFormats[uv.CurrentURL]; ok {
+				statusContent += "\n" + faint.Render("Format: "+format+" (Ctrl+F: change)")
+			} else if entry != nil && entry.Info != nil && len(entry.Info.Formats) > 0 {
+				statusContent += "\n" + faint.Render("Ctrl+F: pick format")
+			}
 		} else {
 			statusStyle = statusStyle.Foreground(lipgloss.Color("9")) // Red
 			statusContent = statusStyle.Render("✗ Invalid URL: " + uv.CurrentURL)
@@ -215,23 +276,31 @@ func (uv URLView) View() string {
 	// Create buttons with appropriate styles
 	downloadButton := "Download"
 	presetsButton := "Presets"
+	downloadsButton := "Downloads"
 
 	// Apply styles based on focus
 	switch uv.FocusState {
 	case FocusDownloadButton:
 		downloadButton = buttonFocusedStyle.Render(downloadButton)
 		presetsButton = buttonStyle.Render(presetsButton)
+		downloadsButton = buttonStyle.Render(downloadsButton)
 	case FocusPresetsButton:
 		downloadButton = buttonStyle.Render(downloadButton)
 		presetsButton = buttonFocusedStyle.Render(presetsButton)
+		downloadsButton = buttonStyle.Render(downloadsButton)
+	case FocusDownloadsButton:
+		downloadButton = buttonStyle.Render(downloadButton)
+		presetsButton = buttonStyle.Render(presetsButton)
+		downloadsButton = buttonFocusedStyle.Render(downloadsButton)
 	default:
 		downloadButton = buttonStyle.Render(downloadButton)
 		presetsButton = buttonStyle.Render(presetsButton)
+		downloadsButton = buttonStyle.Render(downloadsButton)
 	}
 
 	// Create buttons row with space-between layout
-	spacer := strings.Repeat(" ", 15) // Space between buttons
-	buttonsContent := lipgloss.JoinHorizontal(lipgloss.Left, downloadButton, spacer, presetsButton)
+	spacer := strings.Repeat(" ", 8) // Space between buttons
+	buttonsContent := lipgloss.JoinHorizontal(lipgloss.Left, downloadButton, spacer, presetsButton, spacer, downloadsButton)
 
 	// Combine input, status and buttons
 	fullContent := inputContent
@@ -266,9 +335,35 @@ func (uv URLView) View() string {
 	// Add all rows to flexbox
 	uv.FlexBox.AddRows([]*flexbox.Row{topRow, mainRow, bottomRow})
 
+	// The command preview is too wide for the centered column, so it goes below it
+	if uv.CommandPreview != "" {
+		height := uv.FlexBox.GetHeight()
+		uv.FlexBox.SetHeight(max(height-lipgloss.Height(uv.CommandPreview), 0))
+		defer uv.FlexBox.SetHeight(height)
+		return lipgloss.JoinVertical(lipgloss.Left, uv.FlexBox.Render(), uv.CommandPreview)
+	}
+
 	return uv.FlexBox.Render()
 }
 
+// renderMetadataCard renders the title and details of a prefetched video
+func (uv URLView) renderMetadataCard(info *VideoMetadata) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")) // Green
+	card := titleStyle.Render("✓ " + info.Title)
+	if summary := info.Summary(); summary != "" {
+		card += "\n" + lipgloss.NewStyle().Faint(true).Render(summary)
+	}
+	return card
+}
+
+// MetadataFor returns prefetched metadata for url, or nil if there's none
+func (uv URLView) MetadataFor(url string) *VideoMetadata {
+	if entry := uv.Metadata[url]; entry != nil {
+		return entry.Info
+	}
+	return nil
+}
+
 // Focus sets focus to the URLView
 func (uv *URLView) Focus() {
 	uv.FocusState = FocusInput
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// versionTimeout limits how long yt-dlp --version may take
+const versionTimeout = 15 * time.Second
+
+// YtDlpVersionMsg is sent when the startup version check finishes
+type YtDlpVersionMsg struct {
+	Version string
+	Warning string // Empty if yt-dlp is usable
+}
+
+// ytDlpCommand builds a command running the configured yt-dlp executable
+// with its extra leading arguments, followed by args
+func ytDlpCommand(args ...string) *exec.Cmd {
+	return ytDlpCommandContext(context.Background(), args...)
+}
+
+// ytDlpCommandContext is like ytDlpCommand but kills the process when ctx is done
+func ytDlpCommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	path, leading := ytDlpExecutable()
+	return exec.CommandContext(ctx, path, append(leading, args...)...)
+}
+
+// ytDlpExecutable returns the configured executable and its leading arguments
+func ytDlpExecutable() (string, []string) {
+	path := expandHome(strings.TrimSpace(appSettings.YtDlpPath))
+	if path == "" {
+		path = "yt-dlp"
+	}
+	return path, append([]string(nil), appSettings.YtDlpArgs...)
+}
+
+// ytDlpCommandLine returns the command line used for yt-dlp, for logs and messages
+func ytDlpCommandLine(args ...string) string {
+	path, leading := ytDlpExecutable()
+	return strings.Join(append(append([]string{path}, leading...), args...), " ")
+}
+
+// checkYtDlpCmd checks the yt-dlp version in the background
+func checkYtDlpCmd() tea.Cmd {
+	return func() tea.Msg {
+		version, err := checkYtDlp()
+		msg := YtDlpVersionMsg{Version: version}
+		if err != nil {
+			msg.Warning = err.Error()
+		}
+		return msg
+	}
+}
+
+// checkYtDlp runs yt-dlp --version and returns an error if it's missing or older than the configured minimum
+func checkYtDlp() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	output, err := ytDlpCommandContext(ctx, "--version").Output()
+	if err != nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) || errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("yt-dlp not found (%s), set settings.ytdlp_path in the config", ytDlpCommandLine())
+		}
+		return "", fmt.Errorf("%s --version failed: %v", ytDlpCommandLine(), err)
+	}
+
+	// Wrappers may print warnings before the version
+	version := lastLine(string(output))
+	logToFile("yt-dlp version: " + version)
+
+	minimum := strings.TrimSpace(appSettings.MinYtDlpVersion)
+	if minimum != "" && compareVersions(version, minimum) < 0 {
+		return version, fmt.Errorf("yt-dlp %s is older than %s, update it with yt-dlp -U", version, minimum)
+	}
+	return version, nil
+}
+
+// compareVersions compares dotted versions like 2024.08.06 numerically,
+// returning -1, 0 or 1. Missing parts count as 0.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		aNumber, bNumber := versionPart(aParts, i), versionPart(bParts, i)
+		if aNumber < bNumber {
+			return -1
+		}
+		if aNumber > bNumber {
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the numeric value of part i of a version, or 0 if it's missing or not a number
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+	if err != nil {
+		return 0
+	}
+	return number
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxWarnings limits how many distinct warnings are kept per download
+const maxWarnings = 20
+
+// MessageLevel is the severity of a message printed by yt-dlp
+type MessageLevel int
+
+const (
+	LevelWarning MessageLevel = iota
+	LevelError
+)
+
+// YtDlpMessage is a parsed ERROR: or WARNING: line of yt-dlp output
+type YtDlpMessage struct {
+	Level     MessageLevel
+	Extractor string      // e.g. "youtube", empty for messages not coming from an extractor
+	VideoID   string      // ID of the video the message is about, if known
+	Category  FailureKind // What kind of problem the message describes
+	Message   string      // Text without the level, extractor and ID prefixes
+}
+
+// messagePattern splits "ERROR: [extractor] id: message" into its parts;
+// the extractor and the video ID are both optional
+var messagePattern = regexp.MustCompile(`^(ERROR|WARNING):\s*(?:\[([^\]]+)\]\s*(?:([\w-]+):\s+)?)?(.*)$`)
+
+// parseYtDlpMessage parses an ERROR: or WARNING: line, returning false for any other line
+func parseYtDlpMessage(line string) (YtDlpMessage, bool) {
+	match := messagePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return YtDlpMessage{}, false
+	}
+
+	message := YtDlpMessage{
+		Level:     LevelWarning,
+		Extractor: match[2],
+		VideoID:   match[3],
+		Message:   strings.TrimSpace(match[4]),
+	}
+	if match[1] == "ERROR" {
+		message.Level = LevelError
+	}
+	// yt-dlp tags download errors with [download], which isn't an extractor
+	if message.Extractor == "download" {
+		message.Extractor = ""
+	}
+	message.Category = classifyMessage(message.Message)
+	return message, true
+}
+
+// String formats the message the way it's shown in the UI
+func (m YtDlpMessage) String() string {
+	prefix := ""
+	if m.Extractor != "" {
+		prefix = "[" + m.Extractor + "] "
+	}
+	if m.VideoID != "" {
+		prefix += m.VideoID + ": "
+	}
+	return prefix + m.Message
+}
+
+// addMessage records a parsed message, filling Error with the latest error
+func (p *DownloadProgress) addMessage(message YtDlpMessage) {
+	if message.Level == LevelError {
+		p.Errors = append(p.Errors, message)
+		p.Error = message.String()
+		return
+	}
+
+	// The same warning is often printed once per format or fragment
+	for _, warning := range p.Warnings {
+		if warning.Message == message.Message {
+			return
+		}
+	}
+	if len(p.Warnings) < maxWarnings {
+		p.Warnings = append(p.Warnings, message)
+	}
+}
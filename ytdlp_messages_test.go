@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestParseYtDlpMessage(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   YtDlpMessage
+		wantOK bool
+	}{
+		{
+			"ERROR: [youtube] dQw4w9WgXcQ: Video unavailable",
+			YtDlpMessage{Level: LevelError, Extractor: "youtube", VideoID: "dQw4w9WgXcQ", Category: FailureUnavailable, Message: "Video unavailable"},
+			true,
+		},
+		{
+			"WARNING: [youtube] Falling back to generic n function search",
+			YtDlpMessage{Level: LevelWarning, Extractor: "youtube", Message: "Falling back to generic n function search"},
+			true,
+		},
+		{
+			"ERROR: [download] Got error: HTTP Error 429: Too Many Requests",
+			YtDlpMessage{Level: LevelError, Category: FailureRateLimited, Message: "Got error: HTTP Error 429: Too Many Requests"},
+			true,
+		},
+		{
+			"ERROR: unable to download video data: HTTP Error 403: Forbidden",
+			YtDlpMessage{Level: LevelError, Message: "unable to download video data: HTTP Error 403: Forbidden"},
+			true,
+		},
+		{
+			"  WARNING: ffmpeg not found. The downloaded format may not be the best available.  ",
+			YtDlpMessage{Level: LevelWarning, Category: FailureMissingFFmpeg, Message: "ffmpeg not found. The downloaded format may not be the best available."},
+			true,
+		},
+		{"[download]  10.0% of 10.00MiB", YtDlpMessage{}, false},
+		{"Some ERROR: in the middle", YtDlpMessage{}, false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseYtDlpMessage(test.line)
+		if ok != test.wantOK || got != test.want {
+			t.Errorf("parseYtDlpMessage(%q) = %+v, %v, want %+v, %v", test.line, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestYtDlpMessageString(t *testing.T) {
+	tests := []struct {
+		message YtDlpMessage
+		want    string
+	}{
+		{YtDlpMessage{Extractor: "youtube", VideoID: "abc", Message: "Video unavailable"}, "[youtube] abc: Video unavailable"},
+		{YtDlpMessage{Extractor: "youtube", Message: "Retrying"}, "[youtube] Retrying"},
+		{YtDlpMessage{Message: "Conversion failed!"}, "Conversion failed!"},
+	}
+
+	for _, test := range tests {
+		if got := test.message.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestDownloadProgressAddMessage(t *testing.T) {
+	var progress DownloadProgress
+	for _, line := range []string{
+		"WARNING: [youtube] abc: nsig extraction failed",
+		"WARNING: [youtube] abc: nsig extraction failed",
+		"ERROR: [youtube] abc: first",
+		"ERROR: [youtube] abc: Video unavailable",
+	} {
+		parseProgressLine(&progress, line)
+	}
+
+	if len(progress.Warnings) != 1 {
+		t.Errorf("kept %d warnings, want 1 without duplicates", len(progress.Warnings))
+	}
+	if len(progress.Errors) != 2 {
+		t.Errorf("kept %d errors, want 2", len(progress.Errors))
+	}
+	if progress.Error != "[youtube] abc: Video unavailable" {
+		t.Errorf("Error = %q, want the latest error", progress.Error)
+	}
+}
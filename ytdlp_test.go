@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2024.08.06", "2024.08.06", 0},
+		{"2024.08.06", "2024.10.22", -1},
+		{"2024.10.22", "2024.08.06", 1},
+		{"2024.8.6", "2024.08.06", 0},
+		{"2024.08.06.1", "2024.08.06", 1},
+		{"2024.08", "2024.08.06", -1},
+		{"2023.12.30", "2024.01.01", -1},
+		{"2024.08.06", "2024.08.06.0", 0},
+		{"nightly", "2024.08.06", -1},
+	}
+
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestYtDlpExecutable(t *testing.T) {
+	previous := appSettings
+	defer func() { appSettings = previous }()
+
+	tests := []struct {
+		path        string
+		args        []string
+		wantPath    string
+		wantLeading []string
+		wantLine    string
+	}{
+		{"", nil, "yt-dlp", nil, "yt-dlp --version"},
+		{" /opt/bin/yt-dlp ", nil, "/opt/bin/yt-dlp", nil, "/opt/bin/yt-dlp --version"},
+		{"python3", []string{"-m", "yt_dlp"}, "python3", []string{"-m", "yt_dlp"}, "python3 -m yt_dlp --version"},
+	}
+
+	for _, test := range tests {
+		appSettings.YtDlpPath = test.path
+		appSettings.YtDlpArgs = test.args
+
+		path, leading := ytDlpExecutable()
+		if path != test.wantPath || !reflect.DeepEqual(leading, test.wantLeading) {
+			t.Errorf("ytDlpExecutable() with %q %q = %q %q, want %q %q", test.path, test.args, path, leading, test.wantPath, test.wantLeading)
+		}
+		if line := ytDlpCommandLine("--version"); line != test.wantLine {
+			t.Errorf("ytDlpCommandLine() = %q, want %q", line, test.wantLine)
+		}
+	}
+}